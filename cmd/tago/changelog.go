@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+)
+
+// newChangelogCmd creates the command that renders the Markdown changelog between two refs
+// Defaults to the latest tag of the current module prefix up to HEAD
+//
+// newChangelogCmd 创建渲染两个引用之间 Markdown 变更日志的命令
+// 默认从当前模块前缀的最新标签到 HEAD
+func newChangelogCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Tag ordering to pick the latest tag
+	// 选择最新标签的排序方式
+	var moduleFlags = &moduleFlags{}
+	var sortName = string(tagbump.TagSortVersion)
+
+	changelogCmd := &cobra.Command{
+		Use:   "changelog [from] [to]",
+		Short: "Show changelog between tags grouped by commit type",
+		Long:  "Render commits in from..to (default latest tag..HEAD) grouped by Conventional Commit type as Markdown, scoped to the submodule DIR inside a submodule",
+		Args:  cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tagSort, err := tagbump.ParseTagSort(sortName)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			config := &tagbump.ChangelogConfig{
+				TagSort: tagSort,
+			}
+			if len(args) >= 1 {
+				config.FromRef = args[0]
+			}
+			if len(args) == 2 {
+				config.ToRef = args[1]
+			}
+
+			// Use main project prefix at the main module, submodule prefix in a submodule, found by --module or the nearest go.mod
+			// 在主模块使用主项目前缀，在子模块使用子模块前缀，子模块由 --module 或最近的 go.mod 确定
+			if config.ModulePath, err = moduleFlags.resolve(gcm); err != nil {
+				return erero.Wro(err)
+			}
+			var changelog string
+			if config.ModulePath == "" {
+				changelog, err = tagbump.MainChangelog(gcm, config)
+			} else {
+				changelog, err = tagbump.SubModuleChangelog(gcm, config)
+			}
+			if err != nil {
+				return erero.Wro(err)
+			}
+			if output.isJSON() {
+				return output.showJSON(map[string]string{"changelog": changelog})
+			}
+			if _, err := fmt.Fprint(output.stdout, changelog); err != nil {
+				return erero.Wro(err)
+			}
+			return nil
+		},
+	}
+	changelogCmd.Flags().StringVar(&sortName, "sort", string(tagbump.TagSortVersion), "latest tag ordering: version (semver precedence) or date (creation date)")
+	moduleFlags.register(changelogCmd)
+	return changelogCmd
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+)
+
+// bumpFlags holds the flags shared by bump, bump main and bump sub-module commands
+// Each command owns its bumpFlags instance and registers the same flag set
+//
+// bumpFlags 保存 bump、bump main 和 bump sub-module 命令共享的标志
+// 每个命令拥有自己的 bumpFlags 实例并注册相同的标志集合
+type bumpFlags struct {
+	versionBase int    // Version base for automatic carry-over // 用于自动进位的版本基数
+	patchBase   int    // Patch carry-over base, overrides the version base // 补丁版本的进位基数，覆盖版本基数
+	minorBase   int    // Minor carry-over base, overrides the version base // 次版本的进位基数，覆盖版本基数
+	preRelease  string // Pre-release identifier (e.g., rc, beta) // 预发布标识（如 rc, beta）
+	initial     string // First version when the prefix has no tags // 前缀没有标签时的第一个版本
+
+	tagFlags       // Target, repo check, sort, release commit and dry-run flags // 目标、仓库检查、排序、发布提交和演练标志
+	tagObjectFlags // Annotated and signed tag flags // 附注标签和签名标签标志
+	confirmFlags   // Confirmation and push flags // 确认和推送标志
+}
+
+// register binds the bump flags to the given command
+//
+// register 将升级标志绑定到给定命令
+func (flags *bumpFlags) register(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&flags.versionBase, "vb", "b", 0, "version-base-num: 1/10/100 for automatic version carry-over, >=2 skips confirmation unless --interactive")
+	cmd.Flags().IntVar(&flags.patchBase, "patch-base", 0, "patch rolls over into minor at this base, e.g. 100 (default version-base-num)")
+	cmd.Flags().IntVar(&flags.minorBase, "minor-base", 0, "minor rolls over into major at this base, e.g. 10 (default version-base-num)")
+	cmd.Flags().StringVar(&flags.preRelease, "pre", "", "pre-release identifier: rc/beta/alpha, creates vX.Y.Z-rc.1 and continues with -rc.2")
+	cmd.Flags().StringVar(&flags.initial, "initial", "", "create this first version (e.g. v0.1.0) when the prefix has no tags yet")
+	flags.tagFlags.register(cmd)
+	flags.tagObjectFlags.register(cmd)
+	flags.confirmFlags.register(cmd)
+}
+
+// newBumpConfig creates bump config from the optional bump level argument and the flags
+// Accepts at most one argument (major/minor/patch/auto), returns ErrInvalidConfig on unknown arguments
+//
+// newBumpConfig 根据可选的升级级别参数和标志创建升级配置
+// 最多接受一个参数（major/minor/patch/auto），遇到未知参数时返回 ErrInvalidConfig
+func (flags *bumpFlags) newBumpConfig(args []string) (*tagbump.BumpConfig, error) {
+	// Validate that no unexpected arguments are provided
+	// 验证没有提供意外的参数
+	if len(args) > 1 {
+		return nil, erero.WithMessagef(tagbump.ErrInvalidConfig, "unknown args ((%s)) expect one of major/minor/patch/auto", strings.Join(args, " "))
+	}
+
+	// Parse bump level argument, patch when not provided
+	// 解析升级级别参数，未提供时为补丁
+	var levelArg string
+	if len(args) == 1 {
+		levelArg = args[0]
+	}
+	bumpLevel, err := tagbump.ParseBumpLevel(levelArg)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	config := &tagbump.BumpConfig{
+		VersionBase:    flags.versionBase,
+		PatchBase:      flags.patchBase,
+		MinorBase:      flags.minorBase,
+		BumpLevel:      bumpLevel,
+		PreRelease:     flags.preRelease,
+		InitialVersion: flags.initial,
+	}
+	if err := flags.tagFlags.apply(config); err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := flags.tagObjectFlags.apply(config); err != nil {
+		return nil, erero.Wro(err)
+	}
+	flags.confirmFlags.apply(config)
+	return config, nil
+}
+
+// tagFlags holds the flags choosing the target, repo checks, tag ordering, release commit and dry-run
+// Shared by bump, set, init, promote and major-migrate, each command owns its instance
+//
+// tagFlags 保存选择目标、仓库检查、标签排序、发布提交和演练的标志
+// 由 bump、set、init、promote 和 major-migrate 共享，每个命令拥有自己的实例
+type tagFlags struct {
+	targetRef    string   // Commit, branch or tag to put the new tag on // 新标签要打在的提交、分支或标签
+	allowDirty   bool     // Skip repo state checks // 跳过仓库状态检查
+	sortName     string   // Ordering to pick the latest tag: version/date // 选择最新标签的排序方式：version/date
+	changelog    bool     // Commit CHANGELOG.md with the new version before tagging // 打标签之前提交带新版本的 CHANGELOG.md
+	versionFiles []string // Version file specs rewritten before tagging // 打标签之前改写的版本文件描述
+	dryRun       bool     // Print the plan without changing anything // 仅打印计划，不做任何修改
+}
+
+// register binds the target, repo check, sort, release commit and dry-run flags to the given command
+//
+// register 将目标、仓库检查、排序、发布提交和演练标志绑定到给定命令
+func (flags *tagFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flags.targetRef, "ref", "", "commit, branch or tag to put the new tag on (default HEAD)")
+	cmd.Flags().BoolVar(&flags.allowDirty, "allow-dirty", false, "tag even with uncommitted changes, untracked files or an unpushed commit")
+	cmd.Flags().StringVar(&flags.sortName, "sort", string(tagbump.TagSortVersion), "latest tag ordering: version (semver precedence) or date (creation date)")
+	cmd.Flags().BoolVar(&flags.changelog, "changelog", false, "prepend the new version to CHANGELOG.md of the module, commit it and tag that commit")
+	cmd.Flags().StringArrayVar(&flags.versionFiles, "version-file", nil, "write the new version into a module file before tagging: version.go[:Name], VERSION or path=regexp (repeatable)")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "print the prefix, old and new tag, target commit and git commands without changing anything")
+}
+
+// apply sets the target, repo check, sort, release commit and dry-run options on the config
+// Returns ErrInvalidConfig on unknown sort values and wrong version file specs
+//
+// apply 在配置上设置目标、仓库检查、排序、发布提交和演练选项
+// 遇到未知的排序值和错误的版本文件描述时返回 ErrInvalidConfig
+func (flags *tagFlags) apply(config *tagbump.BumpConfig) error {
+	tagSort, err := tagbump.ParseTagSort(flags.sortName)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	versionFiles, err := parseVersionFileFlags(flags.versionFiles)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	config.TargetRef = flags.targetRef
+	config.AllowDirty = flags.allowDirty
+	config.TagSort = tagSort
+	config.UpdateChangelog = flags.changelog
+	config.VersionFiles = versionFiles
+	config.DryRun = flags.dryRun
+	return nil
+}
+
+// moduleFlags holds the --module flag choosing the main project or a submodule
+//
+// moduleFlags 保存选择主项目或子模块的 --module 标志
+type moduleFlags struct {
+	module string // Module DIR, the nearest go.mod when empty // 模块目录，为空时使用最近的 go.mod
+}
+
+// register binds the --module flag to the given command
+//
+// register 将 --module 标志绑定到给定命令
+func (flags *moduleFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flags.module, "module", "", "module DIR, relative to the current DIR or absolute (default nearest go.mod)")
+}
+
+// resolve returns "" at the main module, else the submodule DIR relative to repo root
+//
+// resolve 在主模块时返回 ""，否则返回相对于仓库根目录的子模块目录
+func (flags *moduleFlags) resolve(gcm *gitgo.Gcm) (string, error) {
+	modulePath, err := tagbump.ResolveModulePath(gcm, flags.module)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return modulePath, nil
+}
+
+// tagObjectFlags holds the annotated and signed tag flags shared by bump and promote commands
+//
+// tagObjectFlags 保存 bump 和 promote 命令共享的附注标签和签名标签标志
+type tagObjectFlags struct {
+	annotate    bool   // Create an annotated tag with the default message // 使用默认消息创建附注标签
+	message     string // Annotated tag message template // 附注标签消息模板
+	messageFile string // File holding the annotated tag message template // 保存附注标签消息模板的文件
+	sign        bool   // Create a signed tag // 创建签名标签
+	localUser   string // Signing key passed to git tag --local-user // 传给 git tag --local-user 的签名密钥
+}
+
+// register binds the annotated and signed tag flags to the given command
+//
+// register 将附注标签和签名标签标志绑定到给定命令
+func (flags *tagObjectFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&flags.annotate, "annotate", "a", false, "create an annotated tag, message defaults to \""+tagbump.DefaultTagMessage+"\"")
+	cmd.Flags().StringVarP(&flags.message, "message", "m", "", "annotated tag message, supports {module}, {version} and {tag}")
+	cmd.Flags().StringVarP(&flags.messageFile, "message-file", "F", "", "read the annotated tag message from the given file")
+	cmd.Flags().BoolVarP(&flags.sign, "sign", "s", false, "create a signed tag, gpg or ssh following gpg.format (also enabled by tag.gpgSign)")
+	cmd.Flags().StringVarP(&flags.localUser, "local-user", "u", "", "signing key id or ssh key path, implies --sign (default user.signingKey)")
+}
+
+// apply sets the tag message and signing options on the config, reading the message file when given
+// Returns ErrInvalidConfig when both message and message file are given, or the error reading the file
+//
+// apply 在配置上设置标签消息和签名选项，指定消息文件时读取文件
+// 同时指定消息和消息文件时返回 ErrInvalidConfig，无法读取文件时返回读取错误
+func (flags *tagObjectFlags) apply(config *tagbump.BumpConfig) error {
+	if flags.message != "" && flags.messageFile != "" {
+		return erero.WithMessagef(tagbump.ErrInvalidConfig, "--message and --message-file ((%s)) are both set", flags.messageFile)
+	}
+	config.Annotate = flags.annotate
+	config.TagMessage = flags.message
+	config.Sign = flags.sign
+	config.SigningKey = flags.localUser
+	if flags.messageFile != "" {
+		content, err := os.ReadFile(flags.messageFile)
+		if err != nil {
+			return erero.WithMessagef(err, "cannot read message file ((%s))", flags.messageFile)
+		}
+		config.TagMessage = strings.TrimSpace(string(content))
+		config.Annotate = true
+	}
+	return nil
+}
+
+// confirmFlags holds the confirmation and push flags shared by bump and promote commands
+// Independent of the version base, which only controls carry-over when these are set
+//
+// confirmFlags 保存 bump 和 promote 命令共享的确认和推送标志
+// 与版本基数无关，设置这些标志时版本基数只控制进位
+type confirmFlags struct {
+	yes         bool // Create and push without prompts // 不经提示直接创建和推送
+	noPush      bool // Create the tag without pushing it // 创建标签但不推送
+	interactive bool // Prompt before creating and pushing // 创建和推送之前提示确认
+}
+
+// register binds the confirmation and push flags to the given command
+//
+// register 将确认和推送标志绑定到给定命令
+func (flags *confirmFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&flags.yes, "yes", "y", false, "create and push without prompts, whatever the version base")
+	cmd.Flags().BoolVar(&flags.noPush, "no-push", false, "create the tag locally without pushing it")
+	cmd.Flags().BoolVarP(&flags.interactive, "interactive", "i", false, "prompt before creating and pushing, whatever the version base (needs a terminal)")
+	cmd.MarkFlagsMutuallyExclusive("yes", "interactive")
+}
+
+// apply sets the confirmation and push options on the config
+//
+// apply 在配置上设置确认和推送选项
+func (flags *confirmFlags) apply(config *tagbump.BumpConfig) {
+	config.AutoConfirm = flags.yes
+	config.SkipGitPush = flags.noPush
+	config.Interactive = flags.interactive
+}
+
+// parseVersionFileFlags converts the --version-file specs, relative to the module DIR
+//
+// parseVersionFileFlags 转换 --version-file 描述，路径相对于模块目录
+func parseVersionFileFlags(specs []string) ([]tagbump.VersionFile, error) {
+	versionFiles := make([]tagbump.VersionFile, 0, len(specs))
+	for _, spec := range specs {
+		versionFile, err := tagbump.ParseVersionFile(spec)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		versionFiles = append(versionFiles, versionFile)
+	}
+	return versionFiles, nil
+}
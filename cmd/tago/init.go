@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+)
+
+// newInitTagCmd creates command creating the first tag of a module without tags
+// Resolves main project or submodule prefix from --module or the nearest go.mod
+// Refuses when the prefix is tagged already
+//
+// newInitTagCmd 创建为没有标签的模块创建第一个标签的命令
+// 根据 --module 或最近的 go.mod 解析主项目或子模块前缀
+// 前缀已有标签时拒绝
+func newInitTagCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Init configuration flags
+	// 初始化配置标志
+	var moduleFlags = &moduleFlags{}
+	var versionBase = 0
+	var patchBase = 0
+	var minorBase = 0
+	var tagFlags = &tagFlags{}
+	var objectFlags = &tagObjectFlags{}
+	var promptFlags = &confirmFlags{}
+
+	// Create init command
+	// 创建初始化命令
+	initCmd := &cobra.Command{
+		Use:   "init [version]",
+		Short: "Create the first tag of a module without tags",
+		Long:  "Create the first tag (default " + tagbump.DefaultInitialVersion + ") for the current module prefix, sub/v0.0.1 inside a submodule DIR",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := &tagbump.BumpConfig{
+				VersionBase: versionBase,
+				PatchBase:   patchBase,
+				MinorBase:   minorBase,
+			}
+			if err := tagFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			if err := objectFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			promptFlags.apply(config)
+			output.apply(config)
+
+			var version string
+			if len(args) == 1 {
+				version = args[0]
+			}
+
+			// Use main project prefix at the main module, submodule prefix in a submodule, found by --module or the nearest go.mod
+			// 在主模块使用主项目前缀，在子模块使用子模块前缀，子模块由 --module 或最近的 go.mod 确定
+			var err error
+			if config.ModulePath, err = moduleFlags.resolve(gcm); err != nil {
+				return erero.Wro(err)
+			}
+			var result *tagbump.BumpResult
+			if config.ModulePath == "" {
+				result, err = tagbump.InitMainTag(gcm, version, config)
+			} else {
+				result, err = tagbump.InitSubModuleTag(gcm, version, config)
+			}
+			if err != nil {
+				return erero.Wro(err)
+			}
+			return output.showBumpResult(result)
+		},
+	}
+
+	// Configure init flags
+	// 配置初始化标志
+	initCmd.Flags().IntVarP(&versionBase, "vb", "b", 0, "version-base-num: the first version must fit it, >=2 skips confirmation unless --interactive")
+	initCmd.Flags().IntVar(&patchBase, "patch-base", 0, "patch limit the first version must fit (default version-base-num)")
+	initCmd.Flags().IntVar(&minorBase, "minor-base", 0, "minor limit the first version must fit (default version-base-num)")
+	moduleFlags.register(initCmd)
+	tagFlags.register(initCmd)
+	objectFlags.register(initCmd)
+	promptFlags.register(initCmd)
+	return initCmd
+}
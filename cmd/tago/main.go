@@ -13,11 +13,10 @@ import (
 	"github.com/go-mate/tago/tagbump"
 	"github.com/go-xlan/gitgo"
 	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
 	"github.com/yyle88/eroticgo"
-	"github.com/yyle88/must"
 	"github.com/yyle88/rese"
 	"github.com/yyle88/zaplog"
-	"go.uber.org/zap"
 )
 
 // main initializes and executes the tago command with Git tag management
@@ -31,32 +30,96 @@ func main() {
 	// Get current working DIR as project root
 	// 获取当前工作目录作为项目根目录
 	workRoot := rese.C1(os.Getwd())
-	zaplog.SUG.Debugln(eroticgo.GREEN.Sprint(workRoot))
 
 	// Initialize Git command manager with debug mode
 	// 初始化带调试模式的 Git 命令管理器
 	gcm := gitgo.New(workRoot).WithDebug()
 
+	// Tag ordering of the listing, version precedence by default
+	// 标签列表的排序方式，默认按版本优先级
+	var sortName = string(tagbump.TagSortVersion)
+
+	// Output format and writers shared by all commands
+	// 所有命令共享的输出格式和输出
+	var output = newOutputOptions(os.Stdout, os.Stderr)
+
 	// Create root command for tago CLI
 	// 为 tago CLI 创建根命令
 	rootCmd := cobra.Command{
 		Use:   "tago",
 		Short: "Git tag version management tool",
 		Long:  "tago provides smart Git tag creation, bumping, and version management operations",
-		Run: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Flags and args are valid here, failures from now on need no usage text
+			// 此时标志和参数已校验通过，之后的失败无需打印用法
+			cmd.SilenceUsage = true
+
+			// Move logs to stderr before anything is printed when JSON is requested
+			// 请求 JSON 时在输出任何内容之前将日志移到 stderr
+			if err := output.setup(); err != nil {
+				return erero.Wro(err)
+			}
+			zaplog.SUG.Debugln(eroticgo.GREEN.Sprint(workRoot))
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// Display sorted Git tags when no subcommand is provided
 			// 当没有提供子命令时显示排序的 Git 标签
-			eroticgo.BLUE.ShowMessage(rese.V1(gcm.SortedGitTags()))
+			tagSort, err := tagbump.ParseTagSort(sortName)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			tags, err := tagbump.ListGitTags(gcm, tagSort)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			return output.showTags(tags)
 		},
+		// Errors are printed by showError, as JSON in json mode
+		// 错误由 showError 打印，json 模式下为 JSON
+		SilenceErrors: true,
 	}
+	rootCmd.Flags().StringVar(&sortName, "sort", string(tagbump.TagSortVersion), "tag ordering: version (semver precedence) or date (creation date)")
+	rootCmd.PersistentFlags().StringVarP(&output.format, "output", "o", "text", "output format: text or json (JSON on stdout, logs on stderr)")
 
 	// Add tag bump command with all subcommands
 	// 添加带所有子命令的标签升级命令
-	rootCmd.AddCommand(newGitTagBumpCmd(gcm))
+	rootCmd.AddCommand(newGitTagBumpCmd(gcm, output))
+
+	// Add promote command for pre-release tags
+	// 添加预发布标签的晋升命令
+	rootCmd.AddCommand(newPromoteTagCmd(gcm, output))
+
+	// Add init command for modules without tags
+	// 添加无标签模块的初始化命令
+	rootCmd.AddCommand(newInitTagCmd(gcm, output))
+
+	// Add set command for explicit versions
+	// 添加指定版本的设置命令
+	rootCmd.AddCommand(newSetTagCmd(gcm, output))
 
-	// Execute CLI application
-	// 执行 CLI 应用程序
-	must.Done(rootCmd.Execute())
+	// Add major-migrate command for /vN module paths
+	// 添加 /vN 模块路径的主版本迁移命令
+	rootCmd.AddCommand(newMajorMigrateCmd(gcm, output))
+
+	// Add modules command listing the Go modules of the repo
+	// 添加列出仓库 Go 模块的模块命令
+	rootCmd.AddCommand(newModulesCmd(gcm, output))
+
+	// Add verify command for signed tags
+	// 添加签名标签的验证命令
+	rootCmd.AddCommand(newVerifyTagCmd(gcm, output))
+
+	// Add changelog command for commits between tags
+	// 添加标签之间提交的变更日志命令
+	rootCmd.AddCommand(newChangelogCmd(gcm, output))
+
+	// Execute CLI application, print the failure and exit non-zero when a command fails
+	// 执行 CLI 应用程序，命令失败时打印失败信息并以非零状态退出
+	if err := rootCmd.Execute(); err != nil {
+		output.showError(err)
+		os.Exit(1)
+	}
 }
 
 // newGitTagBumpCmd creates the main tag bump command with version base support
@@ -66,44 +129,43 @@ func main() {
 // newGitTagBumpCmd 创建主要标签升级命令，支持版本基数
 // 提供可配置版本基数的自动标签版本升级
 // 支持主项目和子模块标签管理子命令
-func newGitTagBumpCmd(gcm *gitgo.Gcm) *cobra.Command {
-	// Version base configuration for automatic carry-over
-	// 用于自动进位的版本基数配置
-	var versionBase = 0
+func newGitTagBumpCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Bump flags for version base and pre-release
+	// 版本基数和预发布的升级标志
+	var flags = &bumpFlags{}
 
 	// Create main bump command
 	// 创建主要的 bump 命令
 	tagBumpCmd := &cobra.Command{
-		Use:   "bump",
+		Use:   "bump [major|minor|patch|auto]",
 		Short: "Bump Git tag version with version base support",
 		Long:  "Automatically increment Git tag version with configurable version base (1/10/100) for version control",
-		Run: func(cmd *cobra.Command, args []string) {
-			// Validate that no unexpected arguments are provided
-			// 验证没有提供意外的参数
-			if len(args) > 0 {
-				eroticgo.PINK.ShowMessage("UNKNOWN")
-				zaplog.LOG.Warn("unknown-subcommand-param-args", zap.Strings("args", args))
-				os.Exit(1)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Build bump config from bump level argument and flags
+			// 根据升级级别参数和标志构建升级配置
+			config, err := flags.newBumpConfig(args)
+			if err != nil {
+				return erero.Wro(err)
 			}
+			output.apply(config)
 
 			// Execute tag bump operation and display result
 			// 执行标签升级操作并显示结果
-			success := rese.V1(tagbump.BumpGitTag(gcm, versionBase))
-			if success {
-				eroticgo.BLUE.ShowMessage("SUCCESS")
-			} else {
-				eroticgo.PINK.ShowMessage("FAILURE")
+			result, err := tagbump.BumpGitTagWithConfig(gcm, config)
+			if err != nil {
+				return erero.Wro(err)
 			}
+			return output.showBumpResult(result)
 		},
 	}
-	// Configure version base flag for tag bump command
-	// 为标签升级命令配置版本基数标志
-	tagBumpCmd.Flags().IntVarP(&versionBase, "vb", "b", 0, "version-base-num: 1/10/100 for automatic version carry-over")
+	// Configure bump flags for tag bump command
+	// 为标签升级命令配置升级标志
+	flags.register(tagBumpCmd)
 
 	// Add main project and submodule subcommands
 	// 添加主项目和子模块子命令
-	tagBumpCmd.AddCommand(newMainTagBumpCmd(gcm))
-	tagBumpCmd.AddCommand(newSubModuleTagBumpCmd(gcm))
+	tagBumpCmd.AddCommand(newMainTagBumpCmd(gcm, output))
+	tagBumpCmd.AddCommand(newSubModuleTagBumpCmd(gcm, output))
 	return tagBumpCmd
 }
 
@@ -114,71 +176,89 @@ func newGitTagBumpCmd(gcm *gitgo.Gcm) *cobra.Command {
 // newMainTagBumpCmd 创建主项目标签版本升级命令
 // 处理带版本基数配置的主项目标签操作
 // 在主项目根目录中使用
-func newMainTagBumpCmd(gcm *gitgo.Gcm) *cobra.Command {
-	// Version base configuration for main project tags
-	// 主项目标签的版本基数配置
-	var versionBase = 0
+func newMainTagBumpCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Bump flags for main project tags
+	// 主项目标签的升级标志
+	var flags = &bumpFlags{}
 
 	// Create main project tag bump command
 	// 创建主项目标签升级命令
 	tagBumpCmd := &cobra.Command{
-		Use:   "main",
+		Use:   "main [major|minor|patch|auto]",
 		Short: "Bump main project Git tag version",
 		Long:  "Bump version tag for the main project with configurable version base system",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Build bump config from bump level argument and flags
+			// 根据升级级别参数和标志构建升级配置
+			config, err := flags.newBumpConfig(args)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			output.apply(config)
+
 			// Execute main project tag bump and display result
 			// 执行主项目标签升级并显示结果
-			success := rese.V1(tagbump.BumpMainTag(gcm, versionBase))
-			if success {
-				eroticgo.BLUE.ShowMessage("SUCCESS")
-			} else {
-				eroticgo.PINK.ShowMessage("FAILURE")
+			result, err := tagbump.BumpMainTagWithConfig(gcm, config)
+			if err != nil {
+				return erero.Wro(err)
 			}
+			return output.showBumpResult(result)
 		},
 	}
 
-	// Configure version base flag for main command
-	// 为 main 命令配置版本基数标志
-	tagBumpCmd.Flags().IntVarP(&versionBase, "vb", "b", 0, "version-base-num: 1/10/100 for automatic version carry-over")
+	// Configure bump flags for main command
+	// 为 main 命令配置升级标志
+	flags.register(tagBumpCmd)
 	return tagBumpCmd
 }
 
 // newSubModuleTagBumpCmd creates command for submodule tag version bumping
 // Handles submodule-specific tag operations with path prefix support
-// Requires execution from within a submodule DIR, not the main project root
+// Resolves the submodule by --module or the nearest go.mod, refusing the main module
 //
 // newSubModuleTagBumpCmd 创建子模块标签版本升级命令
 // 处理带路径前缀支持的子模块特定标签操作
-// 需要从子模块目录内执行，而非主项目根目录
-func newSubModuleTagBumpCmd(gcm *gitgo.Gcm) *cobra.Command {
-	// Version base configuration for submodule tags
-	// 子模块标签的版本基数配置
-	var versionBase = 0
+// 通过 --module 或最近的 go.mod 确定子模块，拒绝主模块
+func newSubModuleTagBumpCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Bump flags for submodule tags
+	// 子模块标签的升级标志
+	var flags = &bumpFlags{}
+	var moduleFlags = &moduleFlags{}
 
 	// Create submodule tag bump command
 	// 创建子模块标签升级命令
 	tagBumpCmd := &cobra.Command{
-		Use:   "sub-module",
+		Use:   "sub-module [major|minor|patch|auto]",
 		Short: "Bump submodule Git tag version",
-		Long:  "Bump version tag for submodule with path prefix, must be run from within submodule DIR",
-		Run: func(cmd *cobra.Command, args []string) {
-			// Validate we are inside a submodule, not at project root
-			// 验证我们在子模块内部，而非项目根目录
-			must.Different(rese.C1(os.Getwd()), rese.C1(gcm.GetTopPath()))
+		Long:  "Bump version tag for submodule with path prefix, the module of the nearest go.mod or --module",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Build bump config from bump level argument and flags
+			// 根据升级级别参数和标志构建升级配置
+			config, err := flags.newBumpConfig(args)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			output.apply(config)
+
+			// Resolve the submodule DIR, the main module is refused by BumpSubModuleTag
+			// 解析子模块目录，主模块会被 BumpSubModuleTag 拒绝
+			if config.ModulePath, err = moduleFlags.resolve(gcm); err != nil {
+				return erero.Wro(err)
+			}
 
 			// Execute submodule tag bump and display result
 			// 执行子模块标签升级并显示结果
-			success := rese.V1(tagbump.BumpSubModuleTag(gcm, versionBase))
-			if success {
-				eroticgo.BLUE.ShowMessage("SUCCESS")
-			} else {
-				eroticgo.PINK.ShowMessage("FAILURE")
+			result, err := tagbump.BumpSubModuleTagWithConfig(gcm, config)
+			if err != nil {
+				return erero.Wro(err)
 			}
+			return output.showBumpResult(result)
 		},
 	}
 
-	// Configure version base flag for submodule command
-	// 为子模块命令配置版本基数标志
-	tagBumpCmd.Flags().IntVarP(&versionBase, "vb", "b", 0, "version-base-num: 1/10/100 for automatic version carry-over")
+	// Configure bump flags for submodule command
+	// 为子模块命令配置升级标志
+	flags.register(tagBumpCmd)
+	moduleFlags.register(tagBumpCmd)
 	return tagBumpCmd
 }
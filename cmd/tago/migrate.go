@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+)
+
+// newMajorMigrateCmd creates command migrating the module to the next major version
+// Resolves main project or submodule prefix from --module or the nearest go.mod
+// Commits the /vN module path in go.mod and imports, then tags the major bump on that commit
+//
+// newMajorMigrateCmd 创建将模块迁移到下一个主版本的命令
+// 根据 --module 或最近的 go.mod 解析主项目或子模块前缀
+// 提交 go.mod 和导入中的 /vN 模块路径，然后在该提交上打主版本升级标签
+func newMajorMigrateCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Migrate configuration flags
+	// 迁移配置标志
+	var moduleFlags = &moduleFlags{}
+	var preRelease = ""
+	var tagFlags = &tagFlags{}
+	var objectFlags = &tagObjectFlags{}
+	var promptFlags = &confirmFlags{}
+
+	// Create major-migrate command
+	// 创建主版本迁移命令
+	migrateCmd := &cobra.Command{
+		Use:   "major-migrate",
+		Short: "Migrate module path and imports to the next major and tag it",
+		Long:  "Rewrite the go.mod module line and in-module imports to the /v2 suffix, commit them and tag v2.0.0 on that commit, sub/v2.0.0 inside a submodule DIR",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := &tagbump.BumpConfig{
+				PreRelease: preRelease,
+			}
+			if err := tagFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			if err := objectFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			promptFlags.apply(config)
+			output.apply(config)
+
+			// Use main project prefix at the main module, submodule prefix in a submodule, found by --module or the nearest go.mod
+			// 在主模块使用主项目前缀，在子模块使用子模块前缀，子模块由 --module 或最近的 go.mod 确定
+			var err error
+			if config.ModulePath, err = moduleFlags.resolve(gcm); err != nil {
+				return erero.Wro(err)
+			}
+			var result *tagbump.BumpResult
+			if config.ModulePath == "" {
+				result, err = tagbump.MigrateMajorMainTag(gcm, config)
+			} else {
+				result, err = tagbump.MigrateMajorSubModuleTag(gcm, config)
+			}
+			if err != nil {
+				return erero.Wro(err)
+			}
+			return output.showBumpResult(result)
+		},
+	}
+
+	// Configure major-migrate flags
+	// 配置主版本迁移标志
+	migrateCmd.Flags().StringVar(&preRelease, "pre", "", "pre-release identifier: rc/beta/alpha, tags v2.0.0-rc.1 on the migration commit")
+	moduleFlags.register(migrateCmd)
+	tagFlags.register(migrateCmd)
+	objectFlags.register(migrateCmd)
+	promptFlags.register(migrateCmd)
+	return migrateCmd
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+)
+
+// newModulesCmd creates command listing the Go modules of the repo with their latest tags
+// Modules come from every go.mod in the repo plus the use list of go.work when present
+//
+// newModulesCmd 创建列出仓库中 Go 模块及其最新标签的命令
+// 模块来自仓库中的所有 go.mod，存在 go.work 时再加入其 use 列表
+func newModulesCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Tag ordering to pick the latest tag
+	// 选择最新标签的排序方式
+	var sortName = string(tagbump.TagSortVersion)
+
+	modulesCmd := &cobra.Command{
+		Use:   "modules",
+		Short: "List Go modules with their latest tags",
+		Long:  "Find every go.mod of the repo plus the use list of go.work and show module path, DIR, tag prefix, latest tag and commits touching the DIR since that tag",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tagSort, err := tagbump.ParseTagSort(sortName)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			modules, err := tagbump.ListModules(gcm, tagSort)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			return output.showModules(modules)
+		},
+	}
+	modulesCmd.Flags().StringVar(&sortName, "sort", string(tagbump.TagSortVersion), "latest tag ordering: version (semver precedence) or date (creation date)")
+	return modulesCmd
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-mate/tago/tagbump"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/zaplog"
+)
+
+// outputOptions holds the --output format and the writers shared by all commands
+// In json mode stdout carries only the JSON document, logs and messages go to stderr
+//
+// outputOptions 保存所有命令共享的 --output 格式和输出
+// json 模式下 stdout 只输出 JSON 文档，日志和消息输出到 stderr
+type outputOptions struct {
+	format string    // Output format: text/json // 输出格式：text/json
+	stdout io.Writer // Writer of the results and the JSON document // 结果和 JSON 文档的输出
+	stderr io.Writer // Writer of the messages in json mode // json 模式下消息的输出
+}
+
+// newOutputOptions creates the output options writing to the given stdout and stderr
+//
+// newOutputOptions 创建输出到给定 stdout 和 stderr 的输出选项
+func newOutputOptions(stdout io.Writer, stderr io.Writer) *outputOptions {
+	return &outputOptions{format: "text", stdout: stdout, stderr: stderr}
+}
+
+// setup validates the format and moves logs to stderr in json mode
+// Runs before any command, returns ErrInvalidConfig on unknown formats
+//
+// setup 校验输出格式，json 模式下将日志移到 stderr
+// 在任何命令之前执行，遇到未知格式时返回 ErrInvalidConfig
+func (output *outputOptions) setup() error {
+	switch output.format {
+	case "", "text":
+		return nil
+	case "json":
+		zapLog, err := zaplog.NewZapLog(&zaplog.Config{
+			Debug:       true,
+			Level:       "DEBUG",
+			OutputPaths: []string{"stderr"},
+		})
+		if err != nil {
+			return erero.Wro(err)
+		}
+		zaplog.SetLog(zapLog)
+		return nil
+	default:
+		return erero.WithMessagef(tagbump.ErrInvalidConfig, "unknown output format ((%s)) expect text/json", output.format)
+	}
+}
+
+// isJSON tells whether the JSON document is printed instead of text
+//
+// isJSON 判断是否输出 JSON 文档而非文本
+func (output *outputOptions) isJSON() bool {
+	return output.format == "json"
+}
+
+// apply sends the dry-run plan and prompts of the config to the messages writer
+//
+// apply 将配置的演练计划和提示输出到消息输出
+func (output *outputOptions) apply(config *tagbump.BumpConfig) {
+	config.MessageOutput = output.messages()
+}
+
+// messages returns the writer of messages, stderr in json mode so stdout stays valid JSON
+//
+// messages 返回消息的输出，json 模式下为 stderr 以保证 stdout 是合法的 JSON
+func (output *outputOptions) messages() io.Writer {
+	if output.isJSON() {
+		return output.stderr
+	}
+	return output.stdout
+}
+
+// showMessage prints the message framed like eroticgo ShowMessage on the messages writer
+//
+// showMessage 在消息输出上打印与 eroticgo ShowMessage 格式相同的消息
+func (output *outputOptions) showMessage(color eroticgo.COLOR, message string) {
+	line := color.Sprint(strings.Repeat("-", 40))
+	_, _ = fmt.Fprintln(output.messages(), line+"\n"+line+"\n"+color.Sprint(message)+"\n"+line+"\n"+line)
+}
+
+// showJSON prints the value as one indented JSON document on stdout
+//
+// showJSON 在 stdout 上将值输出为一个缩进的 JSON 文档
+func (output *outputOptions) showJSON(value any) error {
+	encoder := json.NewEncoder(output.stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// showError prints the failure of a command, {"error": "..."} on stdout in json mode
+// Errors marked reported were shown by the command already
+//
+// showError 打印命令的失败，json 模式下在 stdout 输出 {"error": "..."}
+// 标记为已报告的错误已由命令自身展示
+func (output *outputOptions) showError(err error) {
+	if errors.Is(err, errReported) {
+		return
+	}
+	if output.isJSON() {
+		_ = output.showJSON(map[string]string{"error": err.Error()})
+		return
+	}
+	output.showMessage(eroticgo.PINK, err.Error()+"\nFAILURE")
+}
+
+// errReported marks a failure the command has shown already, main exits non-zero without printing it again
+//
+// errReported 标记命令已展示的失败，main 以非零状态退出且不再重复打印
+var errReported = errors.New("failure reported")
+
+// showBumpResult prints what the bump or promote did
+// Text mode ends with SUCCESS, or FAILURE when declined at a prompt
+//
+// showBumpResult 打印升级或晋升执行的操作
+// 文本模式以 SUCCESS 结尾，在提示时被拒绝则以 FAILURE 结尾
+func (output *outputOptions) showBumpResult(result *tagbump.BumpResult) error {
+	if output.isJSON() {
+		return output.showJSON(result)
+	}
+	if result.Declined() {
+		output.showMessage(eroticgo.PINK, result.String())
+		output.showMessage(eroticgo.PINK, "FAILURE")
+		return nil
+	}
+	output.showMessage(eroticgo.BLUE, result.String())
+	output.showMessage(eroticgo.BLUE, "SUCCESS")
+	return nil
+}
+
+// tagDocument is the JSON form of one tag in the root listing
+//
+// tagDocument 是根命令标签列表中一个标签的 JSON 形式
+type tagDocument struct {
+	*tagbump.GitTag
+	TagPrefix  string           `json:"tag_prefix"`  // Version prefix, empty when not a version tag // 版本前缀，非版本标签时为空
+	ModulePath string           `json:"module_path"` // Module DIR of the prefix, empty for the main project // 前缀对应的模块目录，主项目为空
+	Version    *tagbump.Version `json:"version"`     // Version components, null when not a version tag // 版本组件，非版本标签时为 null
+}
+
+// showTags prints the tag listing, one "refs/tags/<name> <date>" line per tag in text mode
+//
+// showTags 打印标签列表，文本模式下每个标签一行 "refs/tags/<name> <date>"
+func (output *outputOptions) showTags(tags []*tagbump.GitTag) error {
+	if !output.isJSON() {
+		lines := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			lines = append(lines, "refs/tags/"+tag.Name+" "+tag.Date)
+		}
+		output.showMessage(eroticgo.BLUE, strings.Join(lines, "\n"))
+		return nil
+	}
+
+	documents := make([]*tagDocument, 0, len(tags))
+	for _, tag := range tags {
+		document := &tagDocument{GitTag: tag}
+		if version, err := tagbump.ParseTagName(tag.Name); err == nil {
+			document.Version = &version
+			document.TagPrefix = version.Prefix
+			if dir := path.Dir(version.Prefix); dir != "." {
+				document.ModulePath = dir
+			}
+		}
+		documents = append(documents, document)
+	}
+	return output.showJSON(documents)
+}
+
+// showModules prints the module listing, an aligned table in text mode
+//
+// showModules 打印模块列表，文本模式下为对齐的表格
+func (output *outputOptions) showModules(modules []*tagbump.ModuleInfo) error {
+	if output.isJSON() {
+		return output.showJSON(modules)
+	}
+
+	var sb strings.Builder
+	writer := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "MODULE\tDIR\tTAG-PREFIX\tLATEST-TAG\tCOMMITS")
+	for _, module := range modules {
+		moduleDIR := module.DIR
+		if moduleDIR == "" {
+			moduleDIR = "."
+		}
+		latestTag := module.LatestTag
+		if latestTag == "" {
+			latestTag = "-"
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\n", module.ModulePath, moduleDIR, module.TagPrefix, latestTag, module.Commits)
+	}
+	if err := writer.Flush(); err != nil {
+		return erero.Wro(err)
+	}
+	output.showMessage(eroticgo.BLUE, strings.TrimRight(sb.String(), "\n"))
+	return nil
+}
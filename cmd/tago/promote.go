@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+)
+
+// newPromoteTagCmd creates command promoting the latest pre-release tag to its final release
+// Resolves main project or submodule prefix from --module or the nearest go.mod
+// Tags the pre-release commit by default, or HEAD with --head
+//
+// newPromoteTagCmd 创建将最新预发布标签晋升为正式版本的命令
+// 根据 --module 或最近的 go.mod 解析主项目或子模块前缀
+// 默认标记预发布提交，使用 --head 时标记 HEAD
+func newPromoteTagCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Promote configuration flags
+	// 晋升配置标志
+	var moduleFlags = &moduleFlags{}
+	var versionBase = 0
+	var promoteAtHead = false
+	var tagFlags = &tagFlags{}
+	var objectFlags = &tagObjectFlags{}
+	var promptFlags = &confirmFlags{}
+
+	// Create promote command
+	// 创建晋升命令
+	promoteCmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Promote latest pre-release tag to final release",
+		Long:  "Promote the latest vX.Y.Z-rc.N tag of the current module prefix to vX.Y.Z on the same commit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := &tagbump.BumpConfig{
+				VersionBase:   versionBase,
+				PromoteAtHead: promoteAtHead,
+			}
+			if err := tagFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			if err := objectFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			promptFlags.apply(config)
+			output.apply(config)
+
+			// Use main project prefix at the main module, submodule prefix in a submodule, found by --module or the nearest go.mod
+			// 在主模块使用主项目前缀，在子模块使用子模块前缀，子模块由 --module 或最近的 go.mod 确定
+			var err error
+			if config.ModulePath, err = moduleFlags.resolve(gcm); err != nil {
+				return erero.Wro(err)
+			}
+			var result *tagbump.BumpResult
+			if config.ModulePath == "" {
+				result, err = tagbump.PromoteMainTag(gcm, config)
+			} else {
+				result, err = tagbump.PromoteSubModuleTag(gcm, config)
+			}
+			if err != nil {
+				return erero.Wro(err)
+			}
+			return output.showBumpResult(result)
+		},
+	}
+
+	// Configure promote flags
+	// 配置晋升标志
+	promoteCmd.Flags().IntVarP(&versionBase, "vb", "b", 0, "version-base-num: >=2 skips confirmation unless --interactive")
+	promoteCmd.Flags().BoolVar(&promoteAtHead, "head", false, "tag the release on HEAD instead of the pre-release commit")
+	moduleFlags.register(promoteCmd)
+	tagFlags.register(promoteCmd)
+	objectFlags.register(promoteCmd)
+	promptFlags.register(promoteCmd)
+	return promoteCmd
+}
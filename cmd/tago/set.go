@@ -0,0 +1,69 @@
+package main
+
+import (
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+)
+
+// newSetTagCmd creates command tagging an explicit version after the latest tag
+// Resolves main project or submodule prefix from --module or the nearest go.mod
+// Refuses versions not greater than the latest tag and existing tags
+//
+// newSetTagCmd 创建在最新标签之后打指定版本标签的命令
+// 根据 --module 或最近的 go.mod 解析主项目或子模块前缀
+// 拒绝不大于最新标签的版本和已存在的标签
+func newSetTagCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	// Set configuration flags
+	// 设置配置标志
+	var moduleFlags = &moduleFlags{}
+	var tagFlags = &tagFlags{}
+	var objectFlags = &tagObjectFlags{}
+	var promptFlags = &confirmFlags{}
+
+	// Create set command
+	// 创建设置命令
+	setCmd := &cobra.Command{
+		Use:   "set <version>",
+		Short: "Tag an explicit version after the latest tag",
+		Long:  "Create the given version (1.2.3, v1.2.3 or sub/v1.2.3) for the current module prefix, it must be greater than the latest tag",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := &tagbump.BumpConfig{}
+			if err := tagFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			if err := objectFlags.apply(config); err != nil {
+				return erero.Wro(err)
+			}
+			promptFlags.apply(config)
+			output.apply(config)
+
+			// Use main project prefix at the main module, submodule prefix in a submodule, found by --module or the nearest go.mod
+			// 在主模块使用主项目前缀，在子模块使用子模块前缀，子模块由 --module 或最近的 go.mod 确定
+			var err error
+			if config.ModulePath, err = moduleFlags.resolve(gcm); err != nil {
+				return erero.Wro(err)
+			}
+			var result *tagbump.BumpResult
+			if config.ModulePath == "" {
+				result, err = tagbump.SetMainTag(gcm, args[0], config)
+			} else {
+				result, err = tagbump.SetSubModuleTag(gcm, args[0], config)
+			}
+			if err != nil {
+				return erero.Wro(err)
+			}
+			return output.showBumpResult(result)
+		},
+	}
+
+	// Configure set flags
+	// 配置设置标志
+	moduleFlags.register(setCmd)
+	tagFlags.register(setCmd)
+	objectFlags.register(setCmd)
+	promptFlags.register(setCmd)
+	return setCmd
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"github.com/go-mate/tago/tagbump"
+	"github.com/go-xlan/gitgo"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+)
+
+// newVerifyTagCmd creates the command that reports the signature status of existing tags
+// Prints one line per tag, exits with failure when any tag lacks a good signature
+//
+// newVerifyTagCmd 创建报告已有标签签名状态的命令
+// 每个标签输出一行，任一标签没有有效签名时以失败退出
+func newVerifyTagCmd(gcm *gitgo.Gcm, output *outputOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify <tag>...",
+		Short: "Verify signatures of Git tags",
+		Long:  "Report the signature status (good/bad/unsigned/lightweight) of the given tags using git verify-tag",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var allGood = true
+			var signatures = make([]*tagbump.TagSignature, 0, len(args))
+			for _, tagName := range args {
+				signature, err := tagbump.VerifyTag(gcm, tagName)
+				if err != nil {
+					return erero.Wro(err)
+				}
+				signatures = append(signatures, signature)
+				if output.isJSON() {
+					allGood = allGood && signature.Status == tagbump.SignatureGood
+					continue
+				}
+
+				line := signature.TagName + " " + string(signature.Status)
+				if signature.Format != "" {
+					line += " " + signature.Format
+				}
+				if signature.Status == tagbump.SignatureGood {
+					output.showMessage(eroticgo.BLUE, line)
+				} else {
+					allGood = false
+					output.showMessage(eroticgo.PINK, line)
+				}
+				if signature.Output != "" {
+					output.showMessage(eroticgo.GREEN, signature.Output)
+				}
+			}
+
+			// The statuses are shown already, exit non-zero without printing them again
+			// 状态已经展示，以非零状态退出且不再重复打印
+			if output.isJSON() {
+				if err := output.showJSON(signatures); err != nil {
+					return erero.Wro(err)
+				}
+				if !allGood {
+					return errReported
+				}
+				return nil
+			}
+			if !allGood {
+				output.showMessage(eroticgo.PINK, "FAILURE")
+				return errReported
+			}
+			output.showMessage(eroticgo.BLUE, "SUCCESS")
+			return nil
+		},
+	}
+}
@@ -7,7 +7,6 @@ require (
 	github.com/go-xlan/gitgo v0.0.17
 	github.com/spf13/cobra v1.10.1
 	github.com/stretchr/testify v1.11.1
-	github.com/yyle88/done v1.0.27
 	github.com/yyle88/erero v1.0.23
 	github.com/yyle88/eroticgo v0.0.4
 	github.com/yyle88/must v0.0.26
@@ -17,6 +16,8 @@ require (
 	github.com/yyle88/runpath v1.0.24
 	github.com/yyle88/zaplog v0.0.26
 	go.uber.org/zap v1.27.0
+	golang.org/x/mod v0.23.0
+	golang.org/x/term v0.29.0
 )
 
 require (
@@ -29,6 +30,7 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
+	github.com/yyle88/done v1.0.27 // indirect
 	github.com/yyle88/mutexmap v1.0.14 // indirect
 	github.com/yyle88/printgo v1.0.5 // indirect
 	github.com/yyle88/sure v0.0.40 // indirect
@@ -36,7 +38,6 @@ require (
 	github.com/yyle88/tern v0.0.8 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/term v0.29.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
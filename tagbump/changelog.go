@@ -0,0 +1,190 @@
+package tagbump
+
+import (
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// ChangelogConfig holds the commit range and scope of a changelog
+//
+// ChangelogConfig 保存变更日志的提交范围和作用域
+type ChangelogConfig struct {
+	FromRef    string  // Start ref, excluded (empty = latest tag of the prefix) // 起始引用，不包含（空值 = 该前缀的最新标签）
+	ToRef      string  // End ref, included (empty = HEAD) // 结束引用，包含（空值 = HEAD）
+	TagSort    TagSort // Ordering to pick the latest tag (empty = version) // 选择最新标签的排序方式（空值 = version）
+	ModulePath string  // Module DIR relative to repo root, scopes commits (empty = whole repo) // 相对于仓库根目录的模块目录，用于限定提交范围（空值 = 整个仓库）
+	Heading    string  // Section heading (empty = ToRef, or "Unreleased" on HEAD) // 章节标题（空值 = ToRef，HEAD 时为 "Unreleased"）
+}
+
+// changelogSection is one group of the changelog with its commit types
+//
+// changelogSection 是变更日志中的一个分组及其提交类型
+type changelogSection struct {
+	title string
+	types []string
+}
+
+// changelogSections lists the changelog groups in display order
+// Breaking changes come first, commits with other types go to the last group
+//
+// changelogSections 按显示顺序列出变更日志分组
+// 破坏性变更排在最前，其它类型的提交归入最后一组
+var changelogSections = []changelogSection{
+	{title: "Features", types: []string{"feat"}},
+	{title: "Bug Fixes", types: []string{"fix"}},
+	{title: "Performance", types: []string{"perf"}},
+	{title: "Reverts", types: []string{"revert"}},
+	{title: "Refactoring", types: []string{"refactor"}},
+	{title: "Documentation", types: []string{"docs"}},
+	{title: "Tests", types: []string{"test"}},
+	{title: "Build and CI", types: []string{"build", "ci"}},
+	{title: "Chores", types: []string{"chore", "style"}},
+}
+
+// MainChangelog renders the changelog of the main project, from its latest v tag by default
+//
+// MainChangelog 渲染主项目的变更日志，默认从最新的 v 标签开始
+func MainChangelog(gcm *gitgo.Gcm, config *ChangelogConfig) (string, error) {
+	tagPrefix := "v"
+	majorGlob, err := moduleMajorGlob(gcm, "", 0)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+	return ChangelogMatchRegexp(gcm, tagPrefix, tagRegexp, config)
+}
+
+// SubModuleChangelog renders the changelog of the current submodule
+// Uses the same submodule path prefix as BumpSubModuleTag and only lists commits touching the submodule DIR
+//
+// SubModuleChangelog 渲染当前子模块的变更日志
+// 使用与 BumpSubModuleTag 相同的子模块路径前缀，仅列出修改子模块目录的提交
+func SubModuleChangelog(gcm *gitgo.Gcm, config *ChangelogConfig) (string, error) {
+	subPath, err := subModulePath(gcm, config.ModulePath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+
+	tagPrefix, major, err := subModuleTagPrefix(gcm, subPath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	majorGlob, err := moduleMajorGlob(gcm, subPath, major)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+
+	subConfig := *config
+	subConfig.ModulePath = subPath
+	return ChangelogMatchRegexp(gcm, tagPrefix, tagRegexp, &subConfig)
+}
+
+// ChangelogMatchRegexp renders the changelog of FromRef..ToRef as Markdown
+// Starts from the latest tag matching the pattern when FromRef is empty, or the first commit without tags
+//
+// ChangelogMatchRegexp 将 FromRef..ToRef 的变更日志渲染为 Markdown
+// FromRef 为空时从匹配模式的最新标签开始，没有标签时从第一个提交开始
+func ChangelogMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, config *ChangelogConfig) (string, error) {
+	fromRef := config.FromRef
+	if fromRef == "" {
+		tagName, err := latestTagMatchRegexp(gcm, tagPrefix, tagRegexp, config.TagSort)
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+		fromRef = tagName
+	}
+	toRef := config.ToRef
+	if toRef == "" {
+		toRef = "HEAD"
+	}
+	zaplog.LOG.Debug("CHANGELOG-RANGE", zap.String("from", fromRef), zap.String("to", toRef), zap.String("module-path", config.ModulePath))
+
+	commits, err := ListCommits(gcm, fromRef, toRef, config.ModulePath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+
+	heading := config.Heading
+	if heading == "" {
+		heading = toRef
+		if toRef == "HEAD" {
+			heading = "Unreleased"
+		}
+	}
+	return RenderChangelog(heading, commits), nil
+}
+
+// RenderChangelog renders commits as a Markdown section grouped by Conventional Commit type
+// Breaking changes are listed in their own group on top, non-conventional commits go to "Other Changes"
+//
+// RenderChangelog 将提交渲染为按 Conventional Commit 类型分组的 Markdown 章节
+// 破坏性变更在最上方单独成组，非规范格式的提交归入 "Other Changes"
+func RenderChangelog(heading string, commits []*ConventionalCommit) string {
+	var sb strings.Builder
+	sb.WriteString("## " + heading + "\n")
+	if len(commits) == 0 {
+		sb.WriteString("\nNo changes.\n")
+		return sb.String()
+	}
+
+	var breaking []*ConventionalCommit
+	groups := make(map[string][]*ConventionalCommit, len(changelogSections))
+	var others []*ConventionalCommit
+	for _, commit := range commits {
+		if commit.Breaking {
+			breaking = append(breaking, commit)
+		}
+		if title := changelogSectionTitle(commit.Type); title != "" {
+			groups[title] = append(groups[title], commit)
+		} else {
+			others = append(others, commit)
+		}
+	}
+
+	writeChangelogGroup(&sb, "Breaking Changes", breaking)
+	for _, section := range changelogSections {
+		writeChangelogGroup(&sb, section.title, groups[section.title])
+	}
+	writeChangelogGroup(&sb, "Other Changes", others)
+	return sb.String()
+}
+
+// changelogSectionTitle returns the section title of a commit type, empty when no section takes it
+//
+// changelogSectionTitle 返回提交类型所属章节的标题，没有对应章节时返回空
+func changelogSectionTitle(commitType string) string {
+	for _, section := range changelogSections {
+		for _, sectionType := range section.types {
+			if sectionType == commitType {
+				return section.title
+			}
+		}
+	}
+	return ""
+}
+
+// writeChangelogGroup writes one group of commits as a Markdown list, skipping empty groups
+//
+// writeChangelogGroup 将一组提交写为 Markdown 列表，跳过空分组
+func writeChangelogGroup(sb *strings.Builder, title string, commits []*ConventionalCommit) {
+	if len(commits) == 0 {
+		return
+	}
+	sb.WriteString("\n### " + title + "\n\n")
+	for _, commit := range commits {
+		sb.WriteString("- ")
+		if commit.Scope != "" {
+			sb.WriteString("**" + commit.Scope + ":** ")
+		}
+		sb.WriteString(commit.Subject)
+		if len(commit.Hash) >= 7 {
+			sb.WriteString(" (" + commit.Hash[:7] + ")")
+		}
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,75 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestRenderChangelog(t *testing.T) {
+	commits := []*ConventionalCommit{
+		{Hash: "1111111aaaa", Type: "fix", Subject: "handle empty input"},
+		{Hash: "2222222bbbb", Type: "feat", Scope: "api", Subject: "add endpoint", Breaking: true},
+		{Hash: "3333333cccc", Subject: "Update readme"},
+	}
+	changelog := RenderChangelog("v1.0.0", commits)
+	t.Log(changelog)
+
+	expected := "## v1.0.0\n" +
+		"\n### Breaking Changes\n\n- **api:** add endpoint (2222222)\n" +
+		"\n### Features\n\n- **api:** add endpoint (2222222)\n" +
+		"\n### Bug Fixes\n\n- handle empty input (1111111)\n" +
+		"\n### Other Changes\n\n- Update readme (3333333)\n"
+	require.Equal(t, expected, changelog)
+
+	require.Equal(t, "## Unreleased\n\nNo changes.\n", RenderChangelog("Unreleased", nil))
+}
+
+func TestSubModuleChangelog(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	subDIR := filepath.Join(tempDIR, "sub")
+	must.Done(os.MkdirAll(subDIR, 0755))
+	must.Done(os.WriteFile(filepath.Join(subDIR, "go.mod"), []byte("module example.com/sub\n"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "chore: add sub"))
+	rese.V1(execConfig.Exec("git", "tag", "sub/v0.1.0"))
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "main.txt"), []byte("main"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "feat: main feature"))
+
+	must.Done(os.WriteFile(filepath.Join(subDIR, "sub.txt"), []byte("sub"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "fix(sub): sub fix"))
+
+	// The submodule changelog starts at sub/v0.1.0 and skips the main project commit
+	changelog, err := SubModuleChangelog(gitgo.New(subDIR), &ChangelogConfig{})
+	require.NoError(t, err)
+	t.Log(changelog)
+	require.Contains(t, changelog, "## Unreleased")
+	require.Contains(t, changelog, "**sub:** sub fix")
+	require.NotContains(t, changelog, "main feature")
+	require.NotContains(t, changelog, "add sub")
+
+	// The main project changelog starts at v0.0.1 and covers all commits
+	changelog, err = MainChangelog(gitgo.New(tempDIR), &ChangelogConfig{})
+	require.NoError(t, err)
+	require.Contains(t, changelog, "main feature")
+	require.Contains(t, changelog, "add sub")
+
+	// An explicit range uses the end ref as heading
+	changelog, err = MainChangelog(gitgo.New(tempDIR), &ChangelogConfig{FromRef: "v0.0.1", ToRef: "sub/v0.1.0"})
+	require.NoError(t, err)
+	require.Contains(t, changelog, "## sub/v0.1.0")
+	require.Contains(t, changelog, "add sub")
+	require.NotContains(t, changelog, "main feature")
+}
@@ -0,0 +1,103 @@
+package tagbump
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+)
+
+// ConventionalCommit holds one commit parsed with the Conventional Commits format
+// Header format: type(scope)!: subject, with optional "BREAKING CHANGE:" footer
+//
+// ConventionalCommit 保存按 Conventional Commits 格式解析的一个提交
+// 标题格式：type(scope)!: subject，可带 "BREAKING CHANGE:" 脚注
+type ConventionalCommit struct {
+	Hash     string // Commit hash // 提交哈希
+	Type     string // Commit type in lowercase (feat, fix, ...), empty when not conventional // 小写的提交类型（feat, fix, ...），非规范格式时为空
+	Scope    string // Optional scope inside parentheses // 括号内的可选范围
+	Subject  string // Subject after the colon, or the whole header when not conventional // 冒号后的主题，非规范格式时为整个标题
+	Breaking bool   // Marked with '!' or a BREAKING CHANGE footer // 使用 '!' 或 BREAKING CHANGE 脚注标记
+}
+
+var (
+	conventionalHeaderRegexp = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s*(.*)$`)
+	breakingFooterRegexp     = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s`)
+)
+
+// ParseConventionalCommit parses a commit message with the Conventional Commits format
+// Messages not in the format keep their header as subject with an empty type
+//
+// ParseConventionalCommit 按 Conventional Commits 格式解析提交消息
+// 不符合格式的消息以标题作为主题，类型为空
+func ParseConventionalCommit(hash string, message string) *ConventionalCommit {
+	message = strings.TrimSpace(message)
+	header, body, _ := strings.Cut(message, "\n")
+	header = strings.TrimSpace(header)
+
+	commit := &ConventionalCommit{
+		Hash:     hash,
+		Subject:  header,
+		Breaking: breakingFooterRegexp.MatchString(body),
+	}
+	if matches := conventionalHeaderRegexp.FindStringSubmatch(header); len(matches) == 5 {
+		commit.Type = strings.ToLower(matches[1])
+		commit.Scope = matches[2]
+		commit.Breaking = commit.Breaking || matches[3] == "!"
+		commit.Subject = matches[4]
+	}
+	return commit
+}
+
+// InferBumpLevel infers the bump level from commits by Conventional Commits rules
+// Breaking changes bump major, feat bumps minor, everything else bumps patch
+//
+// InferBumpLevel 按 Conventional Commits 规则从提交推断升级级别
+// 破坏性变更升级 major，feat 升级 minor，其它升级 patch
+func InferBumpLevel(commits []*ConventionalCommit) BumpLevel {
+	bumpLevel := BumpPatch
+	for _, commit := range commits {
+		if commit.Breaking {
+			return BumpMajor
+		}
+		if commit.Type == "feat" {
+			bumpLevel = BumpMinor
+		}
+	}
+	return bumpLevel
+}
+
+// ListCommits lists the commits in fromRef..toRef parsed as Conventional Commits, newest first
+// Scopes to commits touching modulePath when it is not empty, the path is relative to repo root
+// Lists all commits reachable from toRef when fromRef is empty
+//
+// ListCommits 列出 fromRef..toRef 中按 Conventional Commits 解析的提交，最新的在前
+// modulePath 非空时仅包含修改该路径的提交，路径相对于仓库根目录
+// fromRef 为空时列出 toRef 可达的所有提交
+func ListCommits(gcm *gitgo.Gcm, fromRef string, toRef string, modulePath string) ([]*ConventionalCommit, error) {
+	revision := toRef
+	if fromRef != "" {
+		revision = fromRef + ".." + toRef
+	}
+	args := []string{"log", "--format=%H%x1f%B%x1e", revision}
+	if modulePath != "" {
+		args = append(args, "--", modulePath)
+	}
+	output, err := runGit(gcm, args...)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Split records by RS and fields by US control chars
+	// 按 RS 控制字符分割记录，按 US 控制字符分割字段
+	var commits []*ConventionalCommit
+	for _, record := range strings.Split(output, "\x1e") {
+		hash, message, ok := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if !ok {
+			continue
+		}
+		commits = append(commits, ParseConventionalCommit(hash, message))
+	}
+	return commits, nil
+}
@@ -0,0 +1,73 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	commit := ParseConventionalCommit("abc", "feat(api): add endpoint\n\nmore details")
+	require.Equal(t, "feat", commit.Type)
+	require.Equal(t, "api", commit.Scope)
+	require.Equal(t, "add endpoint", commit.Subject)
+	require.False(t, commit.Breaking)
+
+	commit = ParseConventionalCommit("abc", "fix!: drop old flag")
+	require.Equal(t, "fix", commit.Type)
+	require.True(t, commit.Breaking)
+
+	commit = ParseConventionalCommit("abc", "refactor: rename\n\nBREAKING CHANGE: config renamed")
+	require.True(t, commit.Breaking)
+
+	commit = ParseConventionalCommit("abc", "Update readme")
+	require.Equal(t, "", commit.Type)
+	require.Equal(t, "Update readme", commit.Subject)
+}
+
+func TestInferBumpLevel(t *testing.T) {
+	fix := &ConventionalCommit{Type: "fix"}
+	feat := &ConventionalCommit{Type: "feat"}
+	breaking := &ConventionalCommit{Type: "chore", Breaking: true}
+
+	require.Equal(t, BumpPatch, InferBumpLevel([]*ConventionalCommit{fix}))
+	require.Equal(t, BumpMinor, InferBumpLevel([]*ConventionalCommit{fix, feat}))
+	require.Equal(t, BumpMajor, InferBumpLevel([]*ConventionalCommit{feat, breaking, fix}))
+}
+
+func TestBumpSubModuleTag_Auto(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	subDIR := filepath.Join(tempDIR, "sub")
+	must.Done(os.MkdirAll(subDIR, 0755))
+	must.Done(os.WriteFile(filepath.Join(subDIR, "go.mod"), []byte("module example.com/sub\n"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "chore: add sub"))
+	rese.V1(execConfig.Exec("git", "tag", "sub/v0.1.0"))
+
+	// A feat commit outside the submodule does not count
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "main.txt"), []byte("main"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "feat: main feature"))
+
+	must.Done(os.WriteFile(filepath.Join(subDIR, "sub.txt"), []byte("sub"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "fix(sub): sub fix"))
+
+	gcm := gitgo.New(subDIR)
+	result, err := BumpSubModuleTagWithConfig(gcm, &BumpConfig{VersionBase: 100, BumpLevel: BumpAuto, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	tags := rese.C1(gcm.SortedGitTags())
+	t.Log(tags)
+	require.Contains(t, tags, "refs/tags/sub/v0.1.1")
+}
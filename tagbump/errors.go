@@ -0,0 +1,24 @@
+package tagbump
+
+import "errors"
+
+// Sentinel errors returned by the tagbump functions, wrapped with details, match them with errors.Is
+//
+// tagbump 函数返回的哨兵错误，包装了详细信息，使用 errors.Is 匹配
+var (
+	ErrNoTag               = errors.New("no tag")                                      // No tag matches the prefix // 没有匹配前缀的标签
+	ErrNotSubModule        = errors.New("not in sub-module path")                      // Sub-module operation run at repo root // 在仓库根目录执行子模块操作
+	ErrTagFormat           = errors.New("tag not match version format")                // Tag name is not a semantic version with the prefix // 标签名不是带前缀的语义化版本
+	ErrInvalidConfig       = errors.New("invalid config")                              // Unknown bump level, tag sort or pre-release identifier // 未知的升级级别、排序方式或预发布标识
+	ErrVersionBaseOverflow = errors.New("version component overflows version base")    // Minor or patch of the old tag is not less than the version base // 旧标签的次版本或补丁版本不小于版本基数
+	ErrVersionNotGreater   = errors.New("new version is not greater than old version") // Computed version does not rank above the old one // 计算得到的版本不高于旧版本
+	ErrNotPreRelease       = errors.New("tag is not a pre-release tag")                // Promote run on a final release tag // 在正式版本标签上执行晋升
+	ErrModuleMajorMismatch = errors.New("module path major mismatch")                  // Tag major does not match the /vN suffix in go.mod // 标签主版本与 go.mod 中的 /vN 后缀不匹配
+	ErrTagExists           = errors.New("tag already exists")                          // New tag name is already taken // 新标签名已被占用
+	ErrNoCommits           = errors.New("no commits since tag")                        // Auto bump has nothing to infer from // 自动升级没有可推断的提交
+	ErrDirtyRepo           = errors.New("repo state not clean")                        // Uncommitted changes, untracked files or unpushed commit // 未提交修改、未跟踪文件或未推送提交
+	ErrReleaseCommitFailed = errors.New("release commit failed")                       // Release files cannot be written or committed // 发布文件无法写入或提交
+	ErrTagCreateFailed     = errors.New("tag create failed")                           // git tag failed // git tag 执行失败
+	ErrPushFailed          = errors.New("push failed")                                 // git push failed, the local tag is kept, or with a release commit both the tag and the commit are rolled back // git push 执行失败，保留本地标签，有发布提交时标签和提交都会回滚
+	ErrConfirmFailed       = errors.New("confirm prompt failed")                       // Interactive prompt cannot read an answer // 交互式提示无法读取回答
+)
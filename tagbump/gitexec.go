@@ -0,0 +1,42 @@
+package tagbump
+
+import (
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// runGit runs a git command in the repo top path of gcm and returns the trimmed output
+// Covers the git operations gcm does not provide, such as listing tags with formats
+//
+// runGit 在 gcm 的仓库根路径执行 git 命令并返回去除首尾空白的输出
+// 覆盖 gcm 未提供的 git 操作，例如按格式列出标签
+func runGit(gcm *gitgo.Gcm, args ...string) (string, error) {
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	output, err := osexec.NewExecConfig().WithPath(topPath).Exec("git", args...)
+	if err != nil {
+		zaplog.LOG.Debug("RUN-GIT-FAILED", zap.Strings("args", args), zap.ByteString("output", output), zap.Error(err))
+		return "", erero.Wro(err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// resolveCommit resolves a ref (tag, branch, HEAD, hash) to the commit hash it points to
+// Annotated tags are peeled, so a tag and the commit it marks compare equal
+//
+// resolveCommit 将引用（标签、分支、HEAD、哈希）解析为其指向的提交哈希
+// 附注标签会被剥离，因此标签与其标记的提交比较相等
+func resolveCommit(gcm *gitgo.Gcm, ref string) (string, error) {
+	commitHash, err := runGit(gcm, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", erero.Wrapf(err, "cannot resolve ref ((%s)) to a commit", ref)
+	}
+	return commitHash, nil
+}
@@ -0,0 +1,320 @@
+package tagbump
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// readModulePath reads the module path declared in the go.mod of the given DIR
+// Handles quoted paths and trailing comments, errors when go.mod or the module line is missing
+//
+// readModulePath 读取给定目录下 go.mod 中声明的模块路径
+// 处理带引号的路径和行尾注释，缺少 go.mod 或 module 行时返回错误
+func readModulePath(moduleDIR string) (string, error) {
+	file, err := os.Open(filepath.Join(moduleDIR, "go.mod"))
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		modulePath := fields[1]
+		if strings.HasPrefix(modulePath, `"`) || strings.HasPrefix(modulePath, "`") {
+			unquoted, err := strconv.Unquote(modulePath)
+			if err != nil {
+				return "", erero.Wrapf(err, "wrong module path ((%s)) in go.mod of ((%s))", modulePath, moduleDIR)
+			}
+			modulePath = unquoted
+		}
+		return modulePath, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", erero.Wro(err)
+	}
+	return "", erero.Errorf("no module line in go.mod of ((%s))", moduleDIR)
+}
+
+// moduleMajor returns the major version suffix of a module path, 0 when there is none
+// "example.com/m/v2" gives 2, "gopkg.in/yaml.v3" gives 3, "example.com/m" gives 0
+//
+// moduleMajor 返回模块路径的主版本后缀，没有时返回 0
+// "example.com/m/v2" 返回 2，"gopkg.in/yaml.v3" 返回 3，"example.com/m" 返回 0
+func moduleMajor(modulePath string) int {
+	var suffix string
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		idx := strings.LastIndex(modulePath, ".v")
+		if idx < 0 {
+			return 0
+		}
+		suffix = modulePath[idx+2:]
+	} else {
+		base := path.Base(modulePath)
+		if !strings.HasPrefix(base, "v") {
+			return 0
+		}
+		suffix = base[1:]
+	}
+	major, err := strconv.Atoi(suffix)
+	if err != nil || major < 2 || strconv.Itoa(major) != suffix {
+		return 0
+	}
+	return major
+}
+
+// checkModuleMajor refuses a new tag whose major does not match the go.mod module path of the module DIR
+// Go needs the /vN suffix for v2+ tags and no suffix below v2, nothing is checked without go.mod
+// Checks config MigrateModule instead of go.mod when the release commit migrates the module path
+// A +incompatible module (v2+ tags without /vN) is only refused when the major changes from the old tag
+// That exemption does not apply when the DIR holds major subdirectories, their majors are not the parent's
+//
+// checkModuleMajor 拒绝主版本与模块目录 go.mod 模块路径不匹配的新标签
+// Go 要求 v2 及以上标签带 /vN 后缀，v2 以下不带后缀，没有 go.mod 时不做检查
+// 发布提交迁移模块路径时检查配置 MigrateModule 而非 go.mod
+// +incompatible 模块（v2 及以上标签不带 /vN）仅在主版本相对旧标签变化时被拒绝
+// 目录包含主版本子目录时不适用该豁免，那些主版本不属于父模块
+func checkModuleMajor(gcm *gitgo.Gcm, config *BumpConfig, newTagName string) error {
+	version, err := Parse(newTagName, config.TagPrefix)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	moduleDIR := filepath.Join(topPath, config.ModulePath)
+	modulePath := config.MigrateModule
+	if modulePath == "" {
+		if modulePath, err = readModulePath(moduleDIR); err != nil {
+			return nil
+		}
+	}
+
+	wantMajor := version.Major
+	if wantMajor < 2 {
+		wantMajor = 0
+	}
+	if moduleMajor(modulePath) == wantMajor {
+		return nil
+	}
+
+	// Keep tagging a +incompatible module, its v2+ tags predate go modules and the major stays the same
+	// 继续为 +incompatible 模块打标签，其 v2 及以上标签早于 go modules，且主版本不变
+	if wantMajor >= 2 && moduleMajor(modulePath) == 0 && config.TagName != "" && lowestMajorSubdirectory(moduleDIR) == 0 {
+		if oldVersion, err := Parse(config.TagName, config.TagPrefix); err == nil && oldVersion.Major == version.Major {
+			zaplog.LOG.Debug("MODULE-INCOMPATIBLE-SAME-MAJOR", zap.String("old_tag", config.TagName), zap.String("new_tag", newTagName), zap.String("module_path", modulePath))
+			return nil
+		}
+	}
+	goModName := filepath.ToSlash(filepath.Join(config.ModulePath, "go.mod"))
+	if wantMajor == 0 {
+		return erero.WithMessagef(ErrModuleMajorMismatch, "tag ((%s)) is below v2 but module path ((%s)) in ((%s)) has a major suffix", newTagName, modulePath, goModName)
+	}
+
+	// Point to the major subdirectory when the repo uses that layout
+	// 仓库使用主版本子目录布局时指向该子目录
+	majorDIR := "v" + strconv.Itoa(wantMajor)
+	if _, err := os.Stat(filepath.Join(moduleDIR, majorDIR, "go.mod")); err == nil {
+		return erero.WithMessagef(ErrModuleMajorMismatch, "tag ((%s)) needs the /%s module path, run tago in the major subdirectory ((%s))", newTagName, majorDIR, filepath.ToSlash(filepath.Join(config.ModulePath, majorDIR)))
+	}
+	return erero.WithMessagef(ErrModuleMajorMismatch, "tag ((%s)) needs module path ending in /%s, found ((%s)) in ((%s))", newTagName, majorDIR, modulePath, goModName)
+}
+
+// subModuleTagPrefix returns the tag prefix of the submodule DIR and the major its tags are limited to
+// A major subdirectory (sub/v2 holding module .../v2) tags with the parent prefix sub/v, limited to major 2
+// Other submodules get the sub path prefix and major 0, their majors are resolved by moduleMajorGlob
+//
+// subModuleTagPrefix 返回子模块目录的标签前缀及其标签限定的主版本
+// 主版本子目录（sub/v2 中的模块为 .../v2）使用父级前缀 sub/v，限定主版本为 2
+// 其它子模块使用子路径前缀，主版本为 0，其主版本由 moduleMajorGlob 确定
+func subModuleTagPrefix(gcm *gitgo.Gcm, subPath string) (string, int, error) {
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return "", 0, erero.Wro(err)
+	}
+	if modulePath, err := readModulePath(filepath.Join(topPath, subPath)); err == nil {
+		major := moduleMajor(modulePath)
+		if major >= 2 && filepath.Base(subPath) == "v"+strconv.Itoa(major) {
+			zaplog.LOG.Debug("MAJOR-SUBDIRECTORY", zap.String("sub-path", subPath), zap.String("module-path", modulePath))
+			return filepath.Join(filepath.Dir(subPath), "v"), major, nil
+		}
+	}
+	return filepath.Join(subPath, "v"), 0, nil
+}
+
+// moduleMajorGlob returns the tag glob matching the majors of the module DIR (empty = main project)
+// A major subdirectory matches its major only, a DIR holding vN/go.mod matches the majors below the lowest N
+// Any major is matched otherwise
+//
+// moduleMajorGlob 返回匹配模块目录（空值 = 主项目）主版本的标签通配符
+// 主版本子目录只匹配其主版本，包含 vN/go.mod 的目录匹配低于最小 N 的主版本
+// 其它情况匹配任意主版本
+func moduleMajorGlob(gcm *gitgo.Gcm, moduleDIR string, major int) (string, error) {
+	if major != 0 {
+		return strconv.Itoa(major), nil
+	}
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	if lowestMajor := lowestMajorSubdirectory(filepath.Join(topPath, moduleDIR)); lowestMajor != 0 {
+		zaplog.LOG.Debug("MAJOR-SUBDIRECTORY-LIMITS-PARENT", zap.String("module-dir", moduleDIR), zap.Int("lowest-major", lowestMajor))
+		return majorsBelowGlob(lowestMajor), nil
+	}
+	return "[0-9]*", nil
+}
+
+// lowestMajorSubdirectory returns the lowest N of the vN subdirectories holding a .../vN module, 0 when there is none
+//
+// lowestMajorSubdirectory 返回包含 .../vN 模块的 vN 子目录中最小的 N，没有时返回 0
+func lowestMajorSubdirectory(moduleDIR string) int {
+	entries, err := os.ReadDir(moduleDIR)
+	if err != nil {
+		return 0
+	}
+	var lowestMajor int
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "v") {
+			continue
+		}
+		major, err := strconv.Atoi(entry.Name()[1:])
+		if err != nil || major < 2 || "v"+strconv.Itoa(major) != entry.Name() {
+			continue
+		}
+		modulePath, err := readModulePath(filepath.Join(moduleDIR, entry.Name()))
+		if err != nil || moduleMajor(modulePath) != major {
+			continue
+		}
+		if lowestMajor == 0 || major < lowestMajor {
+			lowestMajor = major
+		}
+	}
+	return lowestMajor
+}
+
+// majorsBelowGlob returns the tag glob matching the majors below the given major
+// Majors 10 and above are left out since a glob cannot count, they need a /vN module anyway
+//
+// majorsBelowGlob 返回匹配低于给定主版本的标签通配符
+// 由于通配符无法计数，10 及以上的主版本不被匹配，它们本来就需要 /vN 模块
+func majorsBelowGlob(major int) string {
+	return "[0-" + strconv.Itoa(min(major, 10)-1) + "]"
+}
+
+// FindModulePath returns the DIR of the nearest go.mod at or above the gcm DIR, relative to repo root
+// An empty path means the main module at repo root, also used when no go.mod is found
+//
+// FindModulePath 返回 gcm 目录或其上级中最近的 go.mod 所在目录，路径相对于仓库根目录
+// 空路径表示仓库根目录的主模块，找不到 go.mod 时同样使用主模块
+func FindModulePath(gcm *gitgo.Gcm) (string, error) {
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	subPath, err := gcm.GetSubPath()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+
+	// Walk up from the current DIR until a go.mod is found or the repo root is passed
+	// 从当前目录向上查找，直到找到 go.mod 或越过仓库根目录
+	for modulePath := subPath; ; modulePath = path.Dir(modulePath) {
+		if modulePath == "." {
+			modulePath = ""
+		}
+		if _, err := os.Stat(filepath.Join(topPath, modulePath, "go.mod")); err == nil {
+			zaplog.LOG.Debug("FOUND-MODULE-PATH", zap.String("sub-path", subPath), zap.String("module-path", modulePath))
+			return modulePath, nil
+		}
+		if modulePath == "" {
+			break
+		}
+	}
+	zaplog.LOG.Debug("NO-GO-MOD-USE-MAIN-PROJECT", zap.String("sub-path", subPath))
+	return "", nil
+}
+
+// ResolveModulePath returns the module DIR relative to repo root, the nearest go.mod when module is empty
+// A relative module is taken from the gcm DIR like a shell path, an absolute one must be inside the repo, the DIR must exist
+//
+// ResolveModulePath 返回相对于仓库根目录的模块目录，module 为空时使用最近的 go.mod
+// 相对路径的 module 像 shell 路径一样从 gcm 目录计算，绝对路径必须位于仓库内，目录必须存在
+func ResolveModulePath(gcm *gitgo.Gcm, module string) (string, error) {
+	if module == "" {
+		return FindModulePath(gcm)
+	}
+	if filepath.IsAbs(module) {
+		return repoModulePath(gcm, module)
+	}
+	subPath, err := gcm.GetSubPath()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return repoModulePath(gcm, filepath.Join(subPath, module))
+}
+
+// repoModulePath checks the module DIR, relative to repo root or absolute, and returns it relative to repo root
+// Returns ErrInvalidConfig when the DIR is outside the repo or does not exist
+//
+// repoModulePath 检查相对于仓库根目录或绝对路径的模块目录，并返回相对于仓库根目录的路径
+// 目录位于仓库外或不存在时返回 ErrInvalidConfig
+func repoModulePath(gcm *gitgo.Gcm, module string) (string, error) {
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	moduleDIR := module
+	if !filepath.IsAbs(moduleDIR) {
+		moduleDIR = filepath.Join(topPath, moduleDIR)
+	}
+	modulePath, err := filepath.Rel(topPath, moduleDIR)
+	if err != nil || modulePath == ".." || strings.HasPrefix(modulePath, ".."+string(filepath.Separator)) {
+		return "", erero.WithMessagef(ErrInvalidConfig, "module ((%s)) is outside repo ((%s))", module, topPath)
+	}
+	if info, err := os.Stat(moduleDIR); err != nil || !info.IsDir() {
+		return "", erero.WithMessagef(ErrInvalidConfig, "module DIR ((%s)) does not exist", module)
+	}
+	if modulePath == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(modulePath), nil
+}
+
+// subModulePath returns the submodule DIR of config ModulePath (relative to repo root, empty = nearest go.mod)
+// Returns ErrNotSubModule at the main module
+//
+// subModulePath 返回配置 ModulePath（相对于仓库根目录，空值 = 最近的 go.mod）对应的子模块目录
+// 为主模块时返回 ErrNotSubModule
+func subModulePath(gcm *gitgo.Gcm, modulePath string) (string, error) {
+	var subPath string
+	var err error
+	if modulePath == "" {
+		subPath, err = FindModulePath(gcm)
+	} else {
+		subPath, err = repoModulePath(gcm, modulePath)
+	}
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	if subPath == "" {
+		return "", erero.Wro(ErrNotSubModule)
+	}
+	return subPath, nil
+}
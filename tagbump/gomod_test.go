@@ -0,0 +1,222 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestReadModulePath(t *testing.T) {
+	tempDIR := t.TempDir()
+
+	_, err := readModulePath(tempDIR)
+	require.Error(t, err)
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "go.mod"), []byte("// comment\nmodule example.com/demo/v2 // trailing\n\ngo 1.22\n"), 0644))
+	require.Equal(t, "example.com/demo/v2", rese.C1(readModulePath(tempDIR)))
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "go.mod"), []byte("module \"example.com/quoted\"\n"), 0644))
+	require.Equal(t, "example.com/quoted", rese.C1(readModulePath(tempDIR)))
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "go.mod"), []byte("go 1.22\n"), 0644))
+	_, err = readModulePath(tempDIR)
+	require.Error(t, err)
+}
+
+func TestModuleMajor(t *testing.T) {
+	require.Equal(t, 0, moduleMajor("example.com/demo"))
+	require.Equal(t, 2, moduleMajor("example.com/demo/v2"))
+	require.Equal(t, 12, moduleMajor("example.com/demo/v12"))
+	require.Equal(t, 3, moduleMajor("gopkg.in/yaml.v3"))
+	require.Equal(t, 0, moduleMajor("example.com/demo/v1"))
+	require.Equal(t, 0, moduleMajor("example.com/demo/v02"))
+	require.Equal(t, 0, moduleMajor("example.com/vendor"))
+}
+
+// commitGoMod writes the go.mod of the DIR and commits it
+// Environment setup must succeed, so we use rese/must for all operations
+func commitGoMod(tempDIR string, moduleDIR string, modulePath string) {
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, moduleDIR), 0755))
+	must.Done(os.WriteFile(filepath.Join(tempDIR, moduleDIR, "go.mod"), []byte("module "+modulePath+"\n\ngo 1.22\n"), 0644))
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "Set module "+modulePath))
+}
+
+func TestBumpTag_ModuleMajor(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	commitGoMod(tempDIR, "", "example.com/demo")
+
+	config := &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", BumpLevel: BumpMajor, AutoConfirm: true, SkipGitPush: true}
+
+	// v1.0.0 needs no suffix
+	result, err := BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0", result.NewTag)
+
+	// v2.0.0 is refused, also in dry-run mode, until go.mod ends in /v2
+	commitTestFile(tempDIR, "test.txt")
+	config.TagName = "v1.0.0"
+	_, err = BumpTag(gcm, config)
+	require.ErrorIs(t, err, ErrModuleMajorMismatch)
+	dryConfig := *config
+	dryConfig.DryRun = true
+	_, err = BumpTag(gcm, &dryConfig)
+	require.ErrorIs(t, err, ErrModuleMajorMismatch)
+
+	commitGoMod(tempDIR, "", "example.com/demo/v2")
+	result, err = BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", result.NewTag)
+
+	// A v1 patch does not fit the /v2 module path either
+	commitTestFile(tempDIR, "next.txt")
+	_, err = SetTag(gcm, "v1.0.1", &BumpConfig{TagName: "v1.0.0", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrModuleMajorMismatch)
+}
+
+func TestBumpTag_ModuleMajorIncompatible(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	commitGoMod(tempDIR, "", "example.com/demo")
+	rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "tag", "v3.1.0"))
+	commitTestFile(tempDIR, "test.txt")
+
+	// A +incompatible repo keeps tagging patches and minors of its current major
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v3.1.0", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v3.1.1", result.NewTag)
+
+	commitTestFile(tempDIR, "next.txt")
+	result, err = BumpTag(gcm, &BumpConfig{TagName: "v3.1.1", TagPrefix: "v", BumpLevel: BumpMinor, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v3.2.0", result.NewTag)
+
+	// Changing the major still needs the /v4 module path
+	commitTestFile(tempDIR, "last.txt")
+	_, err = BumpTag(gcm, &BumpConfig{TagName: "v3.2.0", TagPrefix: "v", BumpLevel: BumpMajor, AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrModuleMajorMismatch)
+}
+
+func TestBumpSubModuleTag_MajorSubdirectory(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitGoMod(tempDIR, "", "example.com/demo")
+	commitGoMod(tempDIR, "v2", "example.com/demo/v2")
+	rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "tag", "v1.4.0"))
+
+	gcm := gitgo.New(filepath.Join(tempDIR, "v2"))
+	config := &BumpConfig{AutoConfirm: true, SkipGitPush: true}
+
+	// The major subdirectory tags with the root prefix, v1 tags do not count
+	_, err := BumpSubModuleTagWithConfig(gcm, config)
+	require.ErrorIs(t, err, ErrNoTag)
+
+	result, err := InitSubModuleTag(gcm, "", config)
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", result.NewTag)
+
+	commitGoMod(tempDIR, "v2", "example.com/demo/v2 // changed")
+	result, err = BumpSubModuleTagWithConfig(gcm, config)
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", result.OldTag)
+	require.Equal(t, "v2.0.1", result.NewTag)
+
+	// The root keeps bumping its v1 line, the v2 tags belong to the major subdirectory
+	result, err = BumpMainTagWithConfig(gitgo.New(tempDIR), &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v1.4.0", result.OldTag)
+	require.Equal(t, "v1.4.1", result.NewTag)
+
+	commitTestFile(tempDIR, "root.txt")
+	result, err = BumpGitTagWithConfig(gitgo.New(tempDIR), &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v1.4.1", result.OldTag)
+	require.Equal(t, "v1.4.2", result.NewTag)
+
+	// Bumping the root to v2 points at the major subdirectory
+	_, err = BumpTag(gitgo.New(tempDIR), &BumpConfig{TagName: "v1.4.0", TagPrefix: "v", BumpLevel: BumpMajor, AllowDirty: true, AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrModuleMajorMismatch)
+	require.Contains(t, err.Error(), "major subdirectory")
+}
+
+func TestResolveModulePath(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitGoMod(tempDIR, "", "example.com/demo")
+	commitGoMod(tempDIR, "mod", "example.com/demo/mod")
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, "mod", "internal", "x"), 0755))
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, "docs"), 0755))
+
+	// The nearest go.mod wins over the current DIR
+	require.Equal(t, "mod", rese.C1(FindModulePath(gitgo.New(filepath.Join(tempDIR, "mod", "internal", "x")))))
+	require.Equal(t, "mod", rese.C1(FindModulePath(gitgo.New(filepath.Join(tempDIR, "mod")))))
+	require.Equal(t, "", rese.V1(FindModulePath(gitgo.New(filepath.Join(tempDIR, "docs")))))
+	require.Equal(t, "", rese.V1(FindModulePath(gitgo.New(tempDIR))))
+
+	// An explicit module is taken from the gcm DIR, like a shell path
+	gcm := gitgo.New(filepath.Join(tempDIR, "docs"))
+	require.Equal(t, "mod", rese.C1(ResolveModulePath(gcm, "../mod")))
+	require.Equal(t, "mod", rese.C1(ResolveModulePath(gcm, "../mod/")))
+	require.Equal(t, "mod", rese.C1(ResolveModulePath(gcm, filepath.Join(tempDIR, "mod"))))
+	require.Equal(t, "docs", rese.C1(ResolveModulePath(gcm, ".")))
+	require.Equal(t, "", rese.V1(ResolveModulePath(gcm, "..")))
+	require.Equal(t, "mod", rese.C1(ResolveModulePath(gitgo.New(tempDIR), "./mod")))
+
+	// A root-relative path from a sub DIR does not silently pick another module
+	_, err := ResolveModulePath(gcm, "mod")
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = ResolveModulePath(gcm, "../../outside")
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = subModulePath(gcm, "")
+	require.ErrorIs(t, err, ErrNotSubModule)
+}
+
+func TestFindModulePath_NoGoMod(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, "docs"), 0755))
+
+	// Without any go.mod the main project is used, at the root and below it
+	require.Equal(t, "", rese.V1(FindModulePath(gitgo.New(tempDIR))))
+	require.Equal(t, "", rese.V1(FindModulePath(gitgo.New(filepath.Join(tempDIR, "docs")))))
+
+	// The submodule commands refuse the main project
+	_, err := subModulePath(gitgo.New(filepath.Join(tempDIR, "docs")), "")
+	require.ErrorIs(t, err, ErrNotSubModule)
+}
+
+func TestBumpSubModuleTag_NearestGoMod(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitGoMod(tempDIR, "mod", "example.com/demo/mod")
+	rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "tag", "mod/v0.1.0"))
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, "mod", "internal", "x"), 0755))
+	commitTestFile(tempDIR, "mod/internal/x/x.go")
+
+	// Running from a package DIR tags the module, not mod/internal/x/v0.0.1
+	result, err := BumpSubModuleTagWithConfig(gitgo.New(filepath.Join(tempDIR, "mod", "internal", "x")), &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "mod/v0.1.1", result.NewTag)
+
+	// The config module is relative to repo root, wherever gcm is
+	commitTestFile(tempDIR, "mod/next.txt")
+	result, err = BumpSubModuleTagWithConfig(gitgo.New(filepath.Join(tempDIR, "mod", "internal", "x")), &BumpConfig{ModulePath: "mod", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "mod/v0.1.2", result.NewTag)
+}
@@ -0,0 +1,141 @@
+package tagbump
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// DefaultInitialVersion is the first tag created when no version is given, as if bumped from v0.0.0
+//
+// DefaultInitialVersion 是未指定版本时创建的第一个标签，相当于从 v0.0.0 升级
+const DefaultInitialVersion = "v0.0.1"
+
+// defaultInitialVersion returns vN.0.0 when the go.mod module path of the module DIR ends in /vN, else DefaultInitialVersion
+//
+// defaultInitialVersion 模块目录 go.mod 模块路径以 /vN 结尾时返回 vN.0.0，否则返回 DefaultInitialVersion
+func defaultInitialVersion(gcm *gitgo.Gcm, moduleDIR string) string {
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return DefaultInitialVersion
+	}
+	modulePath, err := readModulePath(filepath.Join(topPath, moduleDIR))
+	if err != nil {
+		return DefaultInitialVersion
+	}
+	if major := moduleMajor(modulePath); major >= 2 {
+		return "v" + strconv.Itoa(major) + ".0.0"
+	}
+	return DefaultInitialVersion
+}
+
+// InitMainTag creates the first main project tag in a repo without v{major}.{minor}.{patch} tags
+//
+// InitMainTag 在没有 v{major}.{minor}.{patch} 标签的仓库中创建第一个主项目标签
+func InitMainTag(gcm *gitgo.Gcm, version string, config *BumpConfig) (*BumpResult, error) {
+	// Log main project init operation parameters
+	// 记录主项目初始化操作参数
+	zaplog.LOG.Debug("INIT-MAIN-TAG", zap.String("version", version))
+
+	// Use standard 'v' prefix for main project tags
+	// 主项目标签使用标准的 'v' 前缀
+	tagPrefix := "v"
+	majorGlob, err := moduleMajorGlob(gcm, "", 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+
+	return InitTagMatchRegexp(gcm, tagPrefix, tagRegexp, version, config)
+}
+
+// InitSubModuleTag creates the first submodule tag with the submodule path prefix
+// Uses the same submodule path prefix as BumpSubModuleTag
+//
+// InitSubModuleTag 使用子模块路径前缀创建第一个子模块标签
+// 使用与 BumpSubModuleTag 相同的子模块路径前缀
+func InitSubModuleTag(gcm *gitgo.Gcm, version string, config *BumpConfig) (*BumpResult, error) {
+	// Log submodule init operation parameters
+	// 记录子模块初始化操作参数
+	zaplog.LOG.Debug("INIT-SUB-MODULE-TAG", zap.String("version", version))
+
+	// Resolve the submodule DIR from config ModulePath or the nearest go.mod
+	// 根据配置 ModulePath 或最近的 go.mod 解析子模块目录
+	subPath, err := subModulePath(gcm, config.ModulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Construct submodule-specific tag prefix with path, the parent prefix in a major subdirectory
+	// 构建带路径的子模块特定标签前缀，主版本子目录使用父级前缀
+	tagPrefix, major, err := subModuleTagPrefix(gcm, subPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	majorGlob, err := moduleMajorGlob(gcm, subPath, major)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+
+	// Scope repo checks and tag messages to the submodule DIR, working on a copy of the config
+	// 将仓库检查和标签消息限定到子模块目录，在配置副本上操作
+	subConfig := *config
+	subConfig.ModulePath = subPath
+
+	return InitTagMatchRegexp(gcm, tagPrefix, tagRegexp, version, &subConfig)
+}
+
+// InitTagMatchRegexp creates the first tag of the prefix, refusing when a tag matching the pattern exists
+// The config TagName and TagPrefix are filled on a copy, the caller's config stays unchanged
+//
+// InitTagMatchRegexp 创建该前缀的第一个标签，已存在匹配模式的标签时拒绝
+// 配置中的 TagName 和 TagPrefix 填充在副本上，调用方的配置保持不变
+func InitTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, version string, config *BumpConfig) (*BumpResult, error) {
+	// Log regexp matching parameters for debugging
+	// 记录正则匹配参数用于调试
+	zaplog.LOG.Debug("INIT-MATCH-REGEXP-TAG", zap.String("tag-prefix", tagPrefix), zap.String("tag-regexp", tagRegexp))
+
+	// Refuse when the prefix is tagged already, bump is the way forward then
+	// 前缀已有标签时拒绝，此时应使用 bump
+	tagName, err := latestTagMatchRegexp(gcm, tagPrefix, tagRegexp, config.TagSort)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if tagName != "" {
+		return nil, erero.WithMessagef(ErrTagExists, "tag-prefix=((%s)) is tagged with ((%s)), use bump", tagPrefix, tagName)
+	}
+
+	return InitTag(gcm, version, config.withTag("", tagPrefix))
+}
+
+// InitTag creates the first tag with the config TagPrefix
+// An empty version means DefaultInitialVersion, or vN.0.0 when the go.mod module path ends in /vN
+// The version must fit the configured version bases, then goes through the same confirm/create/push flow as BumpTag
+//
+// InitTag 使用配置 TagPrefix 创建第一个标签
+// version 为空时使用 DefaultInitialVersion，go.mod 模块路径以 /vN 结尾时使用 vN.0.0
+// 版本必须符合配置的进位基数，然后与 BumpTag 使用相同的确认/创建/推送流程
+func InitTag(gcm *gitgo.Gcm, version string, config *BumpConfig) (*BumpResult, error) {
+	zaplog.SUG.Infoln("STARTING-INIT-TAG", version, neatjsons.S(config))
+
+	if version == "" {
+		version = defaultInitialVersion(gcm, config.ModulePath)
+	}
+	newVersion, err := parseSetVersion(version, config.TagPrefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := config.checkVersionBase(newVersion); err != nil {
+		return nil, erero.Wro(err)
+	}
+	newTagName := newVersion.String()
+	zaplog.LOG.Info("INIT-TAG-NAME", zap.String("new-tag", newTagName))
+
+	return createVersionTag(gcm, config, newTagName)
+}
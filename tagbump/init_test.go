@@ -0,0 +1,70 @@
+package tagbump
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestInitMainTag(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "tag", "--delete", "v0.0.1"))
+
+	config := &BumpConfig{AutoConfirm: true, SkipGitPush: true}
+
+	// Without tags bump fails, init creates the default first tag
+	_, err := BumpMainTagWithConfig(gcm, config)
+	require.ErrorIs(t, err, ErrNoTag)
+
+	result, err := InitMainTag(gcm, "", config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+	require.Equal(t, "", result.OldTag)
+	require.Equal(t, DefaultInitialVersion, result.NewTag)
+
+	// Init again is refused since the prefix is tagged
+	_, err = InitMainTag(gcm, "v0.1.0", config)
+	require.ErrorIs(t, err, ErrTagExists)
+}
+
+func TestInitSubModuleTag(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	subDIR := filepath.Join(tempDIR, "sub")
+	commitGoMod(tempDIR, "sub", "example.com/demo/sub")
+
+	// The sub-module prefix has no tags even though the main project has
+	result, err := InitSubModuleTag(gitgo.New(subDIR), "0.1.0", &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "sub/v0.1.0", result.NewTag)
+}
+
+func TestBumpMainTag_InitialVersion(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "tag", "--delete", "v0.0.1"))
+
+	// The initial version must fit the version base
+	_, err := BumpMainTagWithConfig(gcm, &BumpConfig{InitialVersion: "v0.12.0", VersionBase: 10, AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrVersionBaseOverflow)
+
+	result, err := BumpMainTagWithConfig(gcm, &BumpConfig{InitialVersion: "v0.1.0", VersionBase: 10, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v0.1.0", result.NewTag)
+
+	// Once tagged the initial version is ignored and bump goes on
+	commitTestFile(tempDIR, "test.txt")
+	result, err = BumpMainTagWithConfig(gcm, &BumpConfig{InitialVersion: "v0.1.0", VersionBase: 10, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v0.1.1", result.NewTag)
+}
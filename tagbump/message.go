@@ -0,0 +1,67 @@
+package tagbump
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+)
+
+// DefaultTagMessage is the annotated tag message template used when Annotate is set without TagMessage
+//
+// DefaultTagMessage 是设置 Annotate 但未设置 TagMessage 时使用的附注标签消息模板
+const DefaultTagMessage = "Release {module} {version}"
+
+// RenderTagMessage expands the placeholders of an annotated tag message template
+// {module} is the module path, {version} is the tag without sub path, {tag} is the full tag name
+//
+// RenderTagMessage 展开附注标签消息模板中的占位符
+// {module} 是模块路径，{version} 是去掉子路径的标签，{tag} 是完整标签名
+func RenderTagMessage(template string, modulePath string, tagName string) string {
+	return strings.NewReplacer(
+		"{module}", modulePath,
+		"{version}", path.Base(tagName),
+		"{tag}", tagName,
+	).Replace(template)
+}
+
+// tagMessage returns the annotated tag message, empty when a lightweight tag is requested
+// TagMessage implies an annotated tag, Annotate or signing alone uses DefaultTagMessage
+//
+// tagMessage 返回附注标签消息，请求轻量标签时返回空
+// 设置 TagMessage 即表示附注标签，仅设置 Annotate 或签名时使用 DefaultTagMessage
+func tagMessage(gcm *gitgo.Gcm, config *BumpConfig, tagName string, sign bool) (string, error) {
+	template := config.TagMessage
+	if template == "" {
+		if !config.Annotate && !sign {
+			return "", nil
+		}
+		template = DefaultTagMessage
+	}
+	modulePath, err := moduleDisplayPath(gcm, config.ModulePath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return RenderTagMessage(template, modulePath, tagName), nil
+}
+
+// moduleDisplayPath returns the go.mod module path of the module DIR
+// Falls back to the module DIR, or the repo DIR name, when there is no go.mod
+//
+// moduleDisplayPath 返回模块目录的 go.mod 模块路径
+// 没有 go.mod 时回退到模块目录，或仓库目录名
+func moduleDisplayPath(gcm *gitgo.Gcm, moduleDIR string) (string, error) {
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	if modulePath, err := readModulePath(filepath.Join(topPath, moduleDIR)); err == nil {
+		return modulePath, nil
+	}
+	if moduleDIR != "" {
+		return filepath.ToSlash(moduleDIR), nil
+	}
+	return filepath.Base(topPath), nil
+}
@@ -0,0 +1,77 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestRenderTagMessage(t *testing.T) {
+	require.Equal(t, "Release example.com/demo v1.2.3", RenderTagMessage(DefaultTagMessage, "example.com/demo", "v1.2.3"))
+	require.Equal(t, "Release example.com/demo/sub v0.1.0 (sub/v0.1.0)", RenderTagMessage("Release {module} {version} ({tag})", "example.com/demo/sub", "sub/v0.1.0"))
+	require.Equal(t, "no placeholders", RenderTagMessage("no placeholders", "example.com/demo", "v1.2.3"))
+}
+
+func TestBumpTag_Annotated(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "go.mod"), []byte("module example.com/demo\n\ngo 1.22\n"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "Add go.mod"))
+
+	result, err := BumpTag(gcm, &BumpConfig{
+		TagName:     "v0.0.1",
+		TagPrefix:   "v",
+		Annotate:    true,
+		AutoConfirm: true,
+		SkipGitPush: true,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	require.Equal(t, "tag", string(rese.V1(execConfig.Exec("git", "cat-file", "-t", "v0.0.2")))[:3])
+	message := rese.V1(execConfig.Exec("git", "tag", "--list", "--format=%(contents)", "v0.0.2"))
+	require.Contains(t, string(message), "Release example.com/demo v0.0.2")
+
+	// A custom message implies an annotated tag, even on a target ref
+	commitTestFile(tempDIR, "test.txt")
+	result, err = BumpTag(gcm, &BumpConfig{
+		TagName:     "v0.0.2",
+		TagPrefix:   "v",
+		TargetRef:   "HEAD",
+		TagMessage:  "Custom notes for {tag}\n\n- first change",
+		AutoConfirm: true,
+		SkipGitPush: true,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	message = rese.V1(execConfig.Exec("git", "tag", "--list", "--format=%(contents)", "v0.0.3"))
+	require.Contains(t, string(message), "Custom notes for v0.0.3")
+	require.Contains(t, string(message), "- first change")
+}
+
+func TestBumpTag_LightweightByDefault(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitTestFile(tempDIR, "test.txt")
+
+	gcm := gitgo.New(tempDIR)
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	require.Equal(t, "commit", string(rese.V1(execConfig.Exec("git", "cat-file", "-t", "v0.0.2")))[:6])
+}
@@ -0,0 +1,310 @@
+package tagbump
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// MigrateMajorMainTag migrates the main project module to the next major version and tags it
+// Rewrites example.com/m to example.com/m/v2 in go.mod and imports, then creates v2.0.0
+//
+// MigrateMajorMainTag 将主项目模块迁移到下一个主版本并打标签
+// 在 go.mod 和导入中将 example.com/m 改写为 example.com/m/v2，然后创建 v2.0.0
+func MigrateMajorMainTag(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
+	// Log main project migrate operation parameters
+	// 记录主项目迁移操作参数
+	zaplog.LOG.Debug("MIGRATE-MAJOR-MAIN-TAG", zap.String("pre-release", config.PreRelease))
+
+	// Use standard 'v' prefix for main project tags
+	// 主项目标签使用标准的 'v' 前缀
+	tagPrefix := "v"
+	majorGlob, err := moduleMajorGlob(gcm, "", 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+
+	return MigrateMajorTagMatchRegexp(gcm, tagPrefix, tagRegexp, config)
+}
+
+// MigrateMajorSubModuleTag migrates the submodule to the next major version and tags it
+// Uses the same submodule path prefix as BumpSubModuleTag, sub/v1.4.0 becomes sub/v2.0.0
+//
+// MigrateMajorSubModuleTag 将子模块迁移到下一个主版本并打标签
+// 使用与 BumpSubModuleTag 相同的子模块路径前缀，sub/v1.4.0 变为 sub/v2.0.0
+func MigrateMajorSubModuleTag(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
+	// Log submodule migrate operation parameters
+	// 记录子模块迁移操作参数
+	zaplog.LOG.Debug("MIGRATE-MAJOR-SUB-MODULE-TAG", zap.String("pre-release", config.PreRelease))
+
+	// Resolve the submodule DIR from config ModulePath or the nearest go.mod
+	// 根据配置 ModulePath 或最近的 go.mod 解析子模块目录
+	subPath, err := subModulePath(gcm, config.ModulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Construct submodule-specific tag prefix with path, a major subdirectory keeps its major
+	// 构建带路径的子模块特定标签前缀，主版本子目录保持其主版本
+	tagPrefix, major, err := subModuleTagPrefix(gcm, subPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if major != 0 {
+		return nil, erero.WithMessagef(ErrModuleMajorMismatch, "major subdirectory ((%s)) holds v%d only, copy it to v%d for the next major", subPath, major, major+1)
+	}
+	majorGlob, err := moduleMajorGlob(gcm, subPath, 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+
+	// Scope the rewrite and repo checks to the submodule DIR, working on a copy of the config
+	// 将改写和仓库检查限定到子模块目录，在配置副本上操作
+	subConfig := *config
+	subConfig.ModulePath = subPath
+
+	return MigrateMajorTagMatchRegexp(gcm, tagPrefix, tagRegexp, &subConfig)
+}
+
+// MigrateMajorTagMatchRegexp migrates the module after the latest tag matching the pattern
+// The config TagName and TagPrefix are filled on a copy, the caller's config stays unchanged
+//
+// MigrateMajorTagMatchRegexp 在匹配模式的最新标签之后迁移模块
+// 配置中的 TagName 和 TagPrefix 填充在副本上，调用方的配置保持不变
+func MigrateMajorTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, config *BumpConfig) (*BumpResult, error) {
+	// Log regexp matching parameters for debugging
+	// 记录正则匹配参数用于调试
+	zaplog.LOG.Debug("MIGRATE-MATCH-REGEXP-TAG", zap.String("tag-prefix", tagPrefix), zap.String("tag-regexp", tagRegexp))
+
+	// Find latest tag matching the pattern, by version precedence or by date
+	// 按版本优先级或日期查找匹配模式的最新标签
+	tagName, err := latestTagMatchRegexp(gcm, tagPrefix, tagRegexp, config.TagSort)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if tagName == "" {
+		return nil, erero.WithMessagef(ErrNoTag, "not match tag name with tag-prefix=((%s)) tag-regexp=((%s))", tagPrefix, tagRegexp)
+	}
+
+	return MigrateMajorTag(gcm, config.withTag(tagName, tagPrefix))
+}
+
+// MigrateMajorTag rewrites the module path of config ModulePath to the next major and tags the major bump
+// The go.mod module line and in-module imports are written in the release commit, the new tag goes on it
+// Goes through the same confirm/create/push flow as BumpTag, the commit is rolled back when tagging fails
+//
+// MigrateMajorTag 将配置 ModulePath 的模块路径改写为下一个主版本并打主版本升级标签
+// go.mod 的 module 行和模块内导入在发布提交中写入，新标签打在该提交上
+// 与 BumpTag 使用相同的确认/创建/推送流程，打标签失败时回滚该提交
+func MigrateMajorTag(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
+	zaplog.SUG.Infoln("STARTING-MIGRATE-MAJOR-TAG", neatjsons.S(config))
+
+	// Compute the next major module path from go.mod
+	// 根据 go.mod 计算下一个主版本的模块路径
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	modulePath, err := readModulePath(filepath.Join(topPath, config.ModulePath))
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	newModulePath, err := nextMajorModulePath(modulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	zaplog.LOG.Info("MIGRATE-MODULE-PATH", zap.String("module-path", modulePath), zap.String("new-module-path", newModulePath))
+
+	// Bump the major of the latest tag, the new module path must match it
+	// 升级最新标签的主版本，新模块路径必须与其匹配
+	version, err := Parse(config.TagName, config.TagPrefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := config.checkVersionBase(version); err != nil {
+		return nil, erero.Wro(err)
+	}
+	migrateConfig := *config
+	migrateConfig.BumpLevel = BumpMajor
+	migrateConfig.MigrateModule = newModulePath
+	newVersion, err := NextVersion(version, &migrateConfig)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	newTagName := newVersion.String()
+	zaplog.LOG.Info("MIGRATE-TAG-NAME", zap.String("old-tag", config.TagName), zap.String("new-tag", newTagName))
+
+	return createVersionTag(gcm, &migrateConfig, newTagName)
+}
+
+// nextMajorModulePath returns the module path of the next major version
+// "example.com/m" gives "example.com/m/v2", "example.com/m/v2" gives "example.com/m/v3"
+//
+// nextMajorModulePath 返回下一个主版本的模块路径
+// "example.com/m" 返回 "example.com/m/v2"，"example.com/m/v2" 返回 "example.com/m/v3"
+func nextMajorModulePath(modulePath string) (string, error) {
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		return "", erero.Errorf("module path ((%s)) gets its major from gopkg.in, not supported", modulePath)
+	}
+	major := moduleMajor(modulePath)
+	if major == 0 {
+		return modulePath + "/v2", nil
+	}
+	return strings.TrimSuffix(modulePath, "/v"+strconv.Itoa(major)) + "/v" + strconv.Itoa(major+1), nil
+}
+
+// migrateModuleFiles rewrites go.mod and the Go files of the module DIR to config MigrateModule
+// Only files with changes are returned, nested modules, vendor, testdata and hidden DIRs are skipped
+// Imports of nested modules keep their paths, those modules are not migrated
+//
+// migrateModuleFiles 将模块目录的 go.mod 和 Go 文件改写为配置 MigrateModule
+// 仅返回有修改的文件，跳过嵌套模块、vendor、testdata 和隐藏目录
+// 嵌套模块的导入保持原路径，这些模块不会被迁移
+func migrateModuleFiles(topPath string, config *BumpConfig) ([]*releaseFile, error) {
+	moduleDIR := filepath.Join(topPath, config.ModulePath)
+	modulePath, err := readModulePath(moduleDIR)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	goModName := filepath.Join(config.ModulePath, "go.mod")
+	goModContent, err := os.ReadFile(filepath.Join(topPath, goModName))
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	files := []*releaseFile{{name: goModName, content: rewriteModuleLine(goModContent, modulePath, config.MigrateModule)}}
+
+	// Collect the Go files first, nested module paths must be known before any import is rewritten
+	// 先收集 Go 文件，改写导入之前必须知道全部嵌套模块路径
+	var goFilePaths []string
+	var nestedModulePaths []string
+	err = filepath.WalkDir(moduleDIR, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path == moduleDIR {
+				return nil
+			}
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				if nestedModulePath, err := readModulePath(path); err == nil {
+					nestedModulePaths = append(nestedModulePaths, nestedModulePath)
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") {
+			goFilePaths = append(goFilePaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	zaplog.LOG.Debug("MIGRATE-NESTED-MODULES", zap.String("module_path", modulePath), zap.Strings("nested_module_paths", nestedModulePaths))
+
+	for _, path := range goFilePaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		newContent, changed, err := rewriteImports(path, content, modulePath, config.MigrateModule, nestedModulePaths)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if changed {
+			name, err := filepath.Rel(topPath, path)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			files = append(files, &releaseFile{name: name, content: newContent})
+		}
+	}
+	return files, nil
+}
+
+// rewriteModuleLine replaces the module path on the module line of go.mod, keeping quotes and comments
+//
+// rewriteModuleLine 替换 go.mod 中 module 行的模块路径，保留引号和注释
+func rewriteModuleLine(content []byte, modulePath string, newModulePath string) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	for idx, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			lines[idx] = strings.Replace(line, modulePath, newModulePath, 1)
+			break
+		}
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+// rewriteImports replaces imports of the module path and its packages with the new module path
+// Imports of the nested module paths and their packages are left unchanged
+// The file is printed back with go/printer in gofmt style, imports sorted again, when any import changed
+//
+// rewriteImports 将模块路径及其包的导入替换为新模块路径
+// 嵌套模块路径及其包的导入保持不变
+// 有导入修改时使用 go/printer 以 gofmt 风格输出文件，并重新排序导入
+func rewriteImports(fileName string, content []byte, modulePath string, newModulePath string, nestedModulePaths []string) ([]byte, bool, error) {
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, fileName, content, parser.ParseComments)
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	var changed bool
+	for _, importSpec := range astFile.Imports {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			return nil, false, erero.Wro(err)
+		}
+		if !isPathOrSubPath(importPath, modulePath) {
+			continue
+		}
+		if slices.ContainsFunc(nestedModulePaths, func(nestedModulePath string) bool {
+			return isPathOrSubPath(importPath, nestedModulePath)
+		}) {
+			continue
+		}
+		importSpec.Path.Value = strconv.Quote(newModulePath + strings.TrimPrefix(importPath, modulePath))
+		changed = true
+	}
+	if !changed {
+		return content, false, nil
+	}
+	ast.SortImports(fset, astFile)
+
+	var buf bytes.Buffer
+	printerConfig := &printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := printerConfig.Fprint(&buf, fset, astFile); err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// isPathOrSubPath reports whether the import path is the given path or a package below it
+//
+// isPathOrSubPath 判断导入路径是否为给定路径或其下的包
+func isPathOrSubPath(importPath string, path string) bool {
+	return importPath == path || strings.HasPrefix(importPath, path+"/")
+}
@@ -0,0 +1,154 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestNextMajorModulePath(t *testing.T) {
+	require.Equal(t, "example.com/demo/v2", rese.C1(nextMajorModulePath("example.com/demo")))
+	require.Equal(t, "example.com/demo/v3", rese.C1(nextMajorModulePath("example.com/demo/v2")))
+	require.Equal(t, "example.com/demo/sub/v2", rese.C1(nextMajorModulePath("example.com/demo/sub")))
+
+	_, err := nextMajorModulePath("gopkg.in/yaml.v3")
+	require.Error(t, err)
+}
+
+func TestRewriteImports(t *testing.T) {
+	content := []byte(`package demo
+
+import (
+	"fmt"
+
+	"example.com/demo/util"
+	"example.com/demox/other"
+	demo "example.com/demo"
+)
+
+var _ = fmt.Sprint(util.X, other.Y, demo.Z)
+`)
+	newContent, changed, err := rewriteImports("demo.go", content, "example.com/demo", "example.com/demo/v2", nil)
+	require.NoError(t, err)
+	require.True(t, changed)
+	t.Log(string(newContent))
+	require.Contains(t, string(newContent), `"example.com/demo/v2/util"`)
+	require.Contains(t, string(newContent), `demo "example.com/demo/v2"`)
+	require.Contains(t, string(newContent), `"example.com/demox/other"`)
+
+	_, changed, err = rewriteImports("other.go", []byte("package other\n\nimport \"fmt\"\n\nvar _ = fmt.Sprint()\n"), "example.com/demo", "example.com/demo/v2", nil)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	// Imports of a nested module and its packages keep their paths
+	newContent, changed, err = rewriteImports("demo.go", content, "example.com/demo", "example.com/demo/v2", []string{"example.com/demo/util"})
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Contains(t, string(newContent), `"example.com/demo/util"`)
+	require.Contains(t, string(newContent), `demo "example.com/demo/v2"`)
+}
+
+func TestRewriteModuleLine(t *testing.T) {
+	content := rewriteModuleLine([]byte("// example.com/demo\nmodule example.com/demo // example.com/demo\n\nrequire example.com/demo-lib v1.0.0\n"), "example.com/demo", "example.com/demo/v2")
+	require.Equal(t, "// example.com/demo\nmodule example.com/demo/v2 // example.com/demo\n\nrequire example.com/demo-lib v1.0.0\n", string(content))
+}
+
+// commitModuleSource writes a go.mod and a Go file importing a package of the module, then commits them
+// Environment setup must succeed, so we use rese/must for all operations
+func commitModuleSource(tempDIR string, moduleDIR string, modulePath string) {
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, moduleDIR, "util"), 0755))
+	must.Done(os.WriteFile(filepath.Join(tempDIR, moduleDIR, "util", "util.go"), []byte("package util\n\nconst X = 1\n"), 0644))
+	must.Done(os.WriteFile(filepath.Join(tempDIR, moduleDIR, "main.go"), []byte("package main\n\nimport (\n\t\"fmt\"\n\n\t\""+modulePath+"/util\"\n)\n\nfunc main() { fmt.Println(util.X) }\n"), 0644))
+	commitGoMod(tempDIR, moduleDIR, modulePath)
+}
+
+func TestMigrateMajorMainTag(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitModuleSource(tempDIR, "", "example.com/demo")
+	rese.V1(execConfig.Exec("git", "tag", "v1.4.2"))
+	baseCommit := strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "rev-parse", "HEAD"))))
+
+	gcm := gitgo.New(tempDIR)
+
+	// Dry-run lists the rewritten files without touching them
+	result, err := MigrateMajorMainTag(gcm, &BumpConfig{DryRun: true, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, ActionDryRun, result.Action)
+	require.Equal(t, "v2.0.0", result.NewTag)
+	require.Contains(t, strings.Join(result.Plan.Commands, "\n"), "# write module path example.com/demo/v2 into main.go")
+	require.Empty(t, strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "status", "--porcelain")))))
+
+	result, err = MigrateMajorMainTag(gcm, &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v1.4.2", result.OldTag)
+	require.Equal(t, "v2.0.0", result.NewTag)
+
+	// The tag lands on the migration commit, with go.mod and imports rewritten
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("v2.0.0")))
+	require.Equal(t, baseCommit, rese.C1(gcm.GitCommitHash("HEAD~1")))
+	require.Equal(t, "example.com/demo/v2", rese.C1(readModulePath(tempDIR)))
+	require.Contains(t, string(rese.V1(os.ReadFile(filepath.Join(tempDIR, "main.go")))), `"example.com/demo/v2/util"`)
+	require.Equal(t, "go.mod\nmain.go", strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "show", "--name-only", "--format=", "HEAD")))))
+	require.Empty(t, strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "status", "--porcelain")))))
+}
+
+func TestMigrateMajorMainTag_NestedModule(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitModuleSource(tempDIR, "sub", "example.com/demo/sub")
+	commitModuleSource(tempDIR, "", "example.com/demo")
+
+	// The parent imports the nested module, in a file walked before the nested go.mod
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "a.go"), []byte("package main\n\nimport \"example.com/demo/sub/util\"\n\nvar _ = util.X\n"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "Import nested module"))
+	rese.V1(execConfig.Exec("git", "tag", "v1.0.0"))
+
+	gcm := gitgo.New(tempDIR)
+	result, err := MigrateMajorMainTag(gcm, &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", result.NewTag)
+
+	// Only the parent's own imports move to /v2, the nested module keeps its path
+	require.Contains(t, string(rese.V1(os.ReadFile(filepath.Join(tempDIR, "main.go")))), `"example.com/demo/v2/util"`)
+	require.Contains(t, string(rese.V1(os.ReadFile(filepath.Join(tempDIR, "a.go")))), `"example.com/demo/sub/util"`)
+	require.Equal(t, "example.com/demo/sub", rese.C1(readModulePath(filepath.Join(tempDIR, "sub"))))
+	require.Equal(t, "go.mod\nmain.go", strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "show", "--name-only", "--format=", "HEAD")))))
+}
+
+func TestMigrateMajorSubModuleTag(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitModuleSource(tempDIR, "", "example.com/demo")
+	commitModuleSource(tempDIR, "sub", "example.com/demo/sub")
+	rese.V1(execConfig.Exec("git", "tag", "sub/v1.0.0"))
+
+	// Migrating the submodule leaves the root module alone
+	result, err := MigrateMajorSubModuleTag(gitgo.New(filepath.Join(tempDIR, "sub")), &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "sub/v2.0.0", result.NewTag)
+	require.Equal(t, "example.com/demo/sub/v2", rese.C1(readModulePath(filepath.Join(tempDIR, "sub"))))
+	require.Equal(t, "example.com/demo", rese.C1(readModulePath(tempDIR)))
+	require.Contains(t, string(rese.V1(os.ReadFile(filepath.Join(tempDIR, "main.go")))), `"example.com/demo/util"`)
+
+	// A v0 tag bumps to v1, which does not fit the /v2 module path, nothing is committed
+	rese.V1(execConfig.Exec("git", "tag", "v0.3.0"))
+	headCommit := rese.C1(gitgo.New(tempDIR).GitCommitHash("HEAD"))
+	_, err = MigrateMajorMainTag(gitgo.New(tempDIR), &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrModuleMajorMismatch)
+	require.Equal(t, headCommit, rese.C1(gitgo.New(tempDIR).GitCommitHash("HEAD")))
+}
@@ -0,0 +1,209 @@
+package tagbump
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"golang.org/x/mod/modfile"
+)
+
+// ModuleInfo describes one Go module of the repository with its latest tag
+//
+// ModuleInfo 描述仓库中的一个 Go 模块及其最新标签
+type ModuleInfo struct {
+	ModulePath string `json:"module_path"` // Module path declared in go.mod // go.mod 中声明的模块路径
+	DIR        string `json:"dir"`         // Module DIR relative to repo root, empty for the main project // 相对于仓库根目录的模块目录，主项目为空
+	TagPrefix  string `json:"tag_prefix"`  // Tag prefix, "v" or "sub/v" like bump main and bump sub-module // 标签前缀，与 bump main 和 bump sub-module 一样为 "v" 或 "sub/v"
+	LatestTag  string `json:"latest_tag"`  // Latest tag of the prefix, empty when not tagged yet // 该前缀的最新标签，尚未打标签时为空
+	Commits    int    `json:"commits"`     // Commits touching the DIR since the latest tag, all of them when not tagged // 自最新标签以来修改该目录的提交数，未打标签时为全部提交数
+}
+
+// ListModules finds the Go modules of the repository and their latest tags, ordered by DIR
+// Uses every go.mod in the repo together with the use directives of go.work at repo root
+// Commits of a module do not count the DIRs of the modules nested in it
+//
+// ListModules 查找仓库中的 Go 模块及其最新标签，按目录排序
+// 使用仓库中的所有 go.mod 以及仓库根目录 go.work 的 use 指令
+// 模块的提交数不统计嵌套在其中的模块目录
+func ListModules(gcm *gitgo.Gcm, tagSort TagSort) ([]*ModuleInfo, error) {
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	moduleDIRs, err := findModuleDIRs(topPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	modules := make([]*ModuleInfo, 0, len(moduleDIRs))
+	for _, moduleDIR := range moduleDIRs {
+		modulePath, err := readModulePath(filepath.Join(topPath, moduleDIR))
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+
+		// Use the same prefix and pattern as bump main and bump sub-module
+		// 使用与 bump main 和 bump sub-module 相同的前缀和模式
+		tagPrefix, major := "v", 0
+		if moduleDIR != "" {
+			if tagPrefix, major, err = subModuleTagPrefix(gcm, moduleDIR); err != nil {
+				return nil, erero.Wro(err)
+			}
+		}
+		majorGlob, err := moduleMajorGlob(gcm, moduleDIR, major)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+		latestTag, err := latestTagMatchRegexp(gcm, tagPrefix, tagRegexp, tagSort)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+
+		// Count commits touching the module DIR, excluding the DIRs of nested modules, the main project covers the repo root
+		// 统计修改模块目录的提交，排除嵌套模块的目录，主项目覆盖仓库根目录
+		revision := "HEAD"
+		if latestTag != "" {
+			revision = latestTag + "..HEAD"
+		}
+		args := []string{"rev-list", "--count", revision, "--", moduleDIRPathspec(moduleDIR)}
+		for _, nestedDIR := range moduleDIRs {
+			if nestedDIR != moduleDIR && (moduleDIR == "" || strings.HasPrefix(nestedDIR, moduleDIR+"/")) {
+				args = append(args, ":(exclude)"+nestedDIR)
+			}
+		}
+		count, err := runGit(gcm, args...)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		commits, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+
+		modules = append(modules, &ModuleInfo{
+			ModulePath: modulePath,
+			DIR:        moduleDIR,
+			TagPrefix:  tagPrefix,
+			LatestTag:  latestTag,
+			Commits:    commits,
+		})
+	}
+	return modules, nil
+}
+
+// moduleDIRPathspec returns the git pathspec of the module DIR, the repo root for the main project
+//
+// moduleDIRPathspec 返回模块目录的 git 路径规范，主项目为仓库根目录
+func moduleDIRPathspec(moduleDIR string) string {
+	if moduleDIR == "" {
+		return "."
+	}
+	return moduleDIR
+}
+
+// findModuleDIRs returns the sorted module DIRs relative to repo root, walking the repo and adding the go.work uses
+// The walk skips hidden, vendor, testdata and "_" DIRs and nested git repos, go.work can still list those
+//
+// findModuleDIRs 返回排序后相对于仓库根目录的模块目录，遍历仓库并加入 go.work 的 use 目录
+// 遍历时跳过隐藏目录、vendor、testdata、"_" 开头的目录和嵌套的 git 仓库，go.work 仍可列出这些目录
+func findModuleDIRs(topPath string) ([]string, error) {
+	workDIRs, err := readWorkUses(topPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var moduleDIRs []string
+	err = filepath.WalkDir(topPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path == topPath {
+				return nil
+			}
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Name() != "go.mod" {
+			return nil
+		}
+		moduleDIR, err := filepath.Rel(topPath, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		if moduleDIR == "." {
+			moduleDIR = ""
+		}
+		moduleDIRs = append(moduleDIRs, filepath.ToSlash(moduleDIR))
+		return nil
+	})
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if workDIRs != nil {
+		zaplog.LOG.Debug("MODULES-WITH-GO-WORK", zap.Strings("walk_dirs", moduleDIRs), zap.Strings("work_dirs", workDIRs))
+		for _, workDIR := range workDIRs {
+			// Skip stale uses whose DIR has no go.mod, go.work can lag behind the repo
+			// 跳过目录中没有 go.mod 的过期 use，go.work 可能落后于仓库
+			if _, err := os.Stat(filepath.Join(topPath, workDIR, "go.mod")); err != nil {
+				zaplog.LOG.Warn("SKIP-GO-WORK-USE-WITHOUT-GO-MOD", zap.String("dir", workDIR), zap.Error(err))
+				continue
+			}
+			moduleDIRs = append(moduleDIRs, workDIR)
+		}
+	}
+	slices.Sort(moduleDIRs)
+	return slices.Compact(moduleDIRs), nil
+}
+
+// readWorkUses reads the use directives of go.work in the DIR, nil when there is no go.work
+// Parses go.work with modfile.ParseWork, the DIRs are relative to the DIR
+//
+// readWorkUses 读取目录中 go.work 的 use 指令，没有 go.work 时返回 nil
+// 使用 modfile.ParseWork 解析 go.work，目录相对于该目录
+func readWorkUses(workDIR string) ([]string, error) {
+	workPath := filepath.Join(workDIR, "go.work")
+	data, err := os.ReadFile(workPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	workFile, err := modfile.ParseWork(workPath, data, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	moduleDIRs := []string{}
+	for _, use := range workFile.Use {
+		moduleDIR, err := filepath.Rel(workDIR, filepath.Join(workDIR, filepath.FromSlash(use.Path)))
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if moduleDIR == ".." || strings.HasPrefix(moduleDIR, ".."+string(filepath.Separator)) {
+			zaplog.LOG.Warn("SKIP-GO-WORK-USE-OUTSIDE-REPO", zap.String("use", use.Path))
+			continue
+		}
+		if moduleDIR == "." {
+			moduleDIR = ""
+		}
+		moduleDIRs = append(moduleDIRs, filepath.ToSlash(moduleDIR))
+	}
+	return moduleDIRs, nil
+}
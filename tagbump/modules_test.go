@@ -0,0 +1,76 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestReadWorkUses(t *testing.T) {
+	tempDIR := t.TempDir()
+	require.Nil(t, rese.V1(readWorkUses(tempDIR)))
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "go.work"), []byte("go 1.22\n\nuse .\n\nuse (\n\t./a // first\n\t\"./b/c\"\n\t../outside\n)\n"), 0644))
+	require.Equal(t, []string{"", "a", "b/c"}, rese.V1(readWorkUses(tempDIR)))
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "go.work"), []byte("go 1.22\n\nuse(\n\t./a\n)\n"), 0644))
+	require.Equal(t, []string{"a"}, rese.V1(readWorkUses(tempDIR)))
+}
+
+func TestListModules(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitGoMod(tempDIR, "", "example.com/demo")
+	commitGoMod(tempDIR, "mod", "example.com/demo/mod")
+	commitGoMod(tempDIR, "tools", "example.com/demo/tools")
+	commitGoMod(tempDIR, "testdata/fake", "example.com/fake")
+	rese.V1(execConfig.Exec("git", "tag", "mod/v0.1.0"))
+	commitTestFile(tempDIR, "mod/a.txt")
+	commitTestFile(tempDIR, "mod/b.txt")
+
+	modules, err := ListModules(gitgo.New(tempDIR), TagSortVersion)
+	require.NoError(t, err)
+	require.Len(t, modules, 3)
+
+	// The main project does not count the commits of mod and tools, testdata is no module
+	require.Equal(t, &ModuleInfo{ModulePath: "example.com/demo", DIR: "", TagPrefix: "v", LatestTag: "v0.0.1", Commits: 2}, modules[0])
+	require.Equal(t, &ModuleInfo{ModulePath: "example.com/demo/mod", DIR: "mod", TagPrefix: "mod/v", LatestTag: "mod/v0.1.0", Commits: 2}, modules[1])
+	require.Equal(t, &ModuleInfo{ModulePath: "example.com/demo/tools", DIR: "tools", TagPrefix: "tools/v", LatestTag: "", Commits: 1}, modules[2])
+
+	// go.work adds the modules the walk skips, the walked ones stay listed, stale uses are skipped
+	commitGoMod(tempDIR, "_tools", "example.com/demo/tools2")
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "go.work"), []byte("go 1.22\n\nuse(\n\t.\n\t./mod\n\t./_tools\n\t./gone\n)\n"), 0644))
+	modules, err = ListModules(gitgo.New(tempDIR), TagSortVersion)
+	require.NoError(t, err)
+	require.Len(t, modules, 4)
+	require.Equal(t, []string{"", "_tools", "mod", "tools"}, []string{modules[0].DIR, modules[1].DIR, modules[2].DIR, modules[3].DIR})
+	require.Equal(t, 2, modules[0].Commits)
+	require.Equal(t, 1, modules[1].Commits)
+}
+
+func TestListModules_MajorSubdirectory(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitGoMod(tempDIR, "", "example.com/demo")
+	rese.V1(execConfig.Exec("git", "tag", "v1.4.0"))
+	commitGoMod(tempDIR, "v2", "example.com/demo/v2")
+	rese.V1(execConfig.Exec("git", "tag", "v2.0.0"))
+
+	modules, err := ListModules(gitgo.New(tempDIR), TagSortVersion)
+	require.NoError(t, err)
+	require.Len(t, modules, 2)
+
+	// The root row only counts the majors below the major subdirectory
+	require.Equal(t, &ModuleInfo{ModulePath: "example.com/demo", DIR: "", TagPrefix: "v", LatestTag: "v1.4.0", Commits: 0}, modules[0])
+	require.Equal(t, &ModuleInfo{ModulePath: "example.com/demo/v2", DIR: "v2", TagPrefix: "v", LatestTag: "v2.0.0", Commits: 0}, modules[1])
+}
@@ -0,0 +1,141 @@
+package tagbump
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+)
+
+// BumpPlan describes what a bump would do, computed in dry-run mode without touching the repo
+//
+// BumpPlan 描述升级将要执行的操作，在演练模式下计算且不修改仓库
+type BumpPlan struct {
+	TagPrefix    string   `json:"tag_prefix"`    // Resolved tag prefix (e.g., "v", "sub/v") // 解析得到的标签前缀（如 "v", "sub/v"）
+	OldTag       string   `json:"old_tag"`       // Latest tag bumped from // 作为升级起点的最新标签
+	NewTag       string   `json:"new_tag"`       // Computed new tag, empty when the old tag is pushed again // 计算得到的新标签，重新推送旧标签时为空
+	TargetCommit string   `json:"target_commit"` // Commit the tag goes on, the base of the release commit when there is one // 标签要打在的提交，有发布提交时为其父提交
+	Preflight    string   `json:"preflight"`     // Repo state check outcome, "ok", "skipped (allow-dirty)" or "failed: ..." // 仓库状态检查结果，"ok"、"skipped (allow-dirty)" 或 "failed: ..."
+	Commands     []string `json:"commands"`      // Commands that would run, in order // 将要按顺序执行的命令
+}
+
+// String renders the plan as readable lines
+//
+// String 将计划渲染为可读的文本行
+func (plan *BumpPlan) String() string {
+	var sb strings.Builder
+	sb.WriteString("tag-prefix:    " + plan.TagPrefix + "\n")
+	sb.WriteString("old-tag:       " + plan.OldTag + "\n")
+	sb.WriteString("new-tag:       " + plan.NewTag + "\n")
+	sb.WriteString("target-commit: " + plan.TargetCommit + "\n")
+	sb.WriteString("preflight:     " + plan.Preflight + "\n")
+	sb.WriteString("commands:\n")
+	for _, command := range plan.Commands {
+		sb.WriteString("  " + command + "\n")
+	}
+	return sb.String()
+}
+
+// showPlan prints the dry-run plan to the writer, framed like eroticgo ShowMessage
+//
+// showPlan 将演练计划打印到 writer，格式与 eroticgo ShowMessage 相同
+func showPlan(writer io.Writer, plan *BumpPlan) {
+	line := eroticgo.AMBER.Sprint(strings.Repeat("-", 40))
+	_, _ = fmt.Fprintln(writer, line+"\n"+line+"\n"+eroticgo.AMBER.Sprint("DRY-RUN, nothing is changed\n"+plan.String())+"\n"+line+"\n"+line)
+}
+
+// planCreateAndPushTag computes the commands createAndPushTag would run for the new tag
+//
+// planCreateAndPushTag 计算 createAndPushTag 为新标签将要执行的命令
+func planCreateAndPushTag(gcm *gitgo.Gcm, config *BumpConfig, newTagName string, targetRef string) (*BumpPlan, error) {
+	headCommit, err := resolveCommit(gcm, "HEAD")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	plan := &BumpPlan{
+		TagPrefix:    config.TagPrefix,
+		OldTag:       config.TagName,
+		NewTag:       newTagName,
+		TargetCommit: headCommit,
+	}
+	if targetRef != "" {
+		if plan.TargetCommit, err = resolveCommit(gcm, targetRef); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	plan.Preflight = preflightStatus(gcm, config, plan.TargetCommit)
+
+	var pushArgs []string
+	if config.needsReleaseCommit() {
+		if plan.TargetCommit != headCommit {
+			return nil, erero.Errorf("release commit needs the new tag on HEAD, target ((%s)) is not HEAD", targetRef)
+		}
+		branch, err := runGit(gcm, "symbolic-ref", "--quiet", "--short", "HEAD")
+		if err != nil {
+			return nil, erero.WithMessage(err, "release commit needs a branch, HEAD is detached")
+		}
+		message, err := releaseCommitMessage(gcm, config, newTagName)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		// Render the release files like the real run, so unreadable or mismatching version files fail here
+		// 与实际运行一样渲染发布文件，使无法读取或不匹配的版本文件在此失败
+		topPath, err := gcm.GetTopPath()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		files, err := renderReleaseFiles(gcm, config, newTagName, topPath, headCommit)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		releaseNames := releaseFileNames(config)
+		names := make([]string, 0, len(files))
+		for _, file := range files {
+			if !slices.Contains(releaseNames, file.name) {
+				plan.Commands = append(plan.Commands, "# write module path "+config.MigrateModule+" into "+file.name)
+			}
+			names = append(names, file.name)
+		}
+		for _, name := range releaseNames {
+			plan.Commands = append(plan.Commands, "# write new version into "+name)
+		}
+		plan.Commands = append(plan.Commands,
+			formatGitCommand(append([]string{"add", "--"}, names...)),
+			formatGitCommand(append([]string{"commit", "--message", message, "--"}, names...)),
+		)
+		targetRef = ""
+		pushArgs = releasePushArgs(gcm, branch, newTagName)
+	} else {
+		pushArgs = []string{"push", "origin", newTagName}
+	}
+
+	tagArgs, err := tagCommandArgs(gcm, config, newTagName, targetRef)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	plan.Commands = append(plan.Commands, formatGitCommand(tagArgs))
+	if !config.SkipGitPush {
+		plan.Commands = append(plan.Commands, formatGitCommand(pushArgs))
+	}
+	return plan, nil
+}
+
+// formatGitCommand renders git args as a shell command line, quoting args with spaces or quotes
+//
+// formatGitCommand 将 git 参数渲染为 shell 命令行，为包含空格或引号的参数加引号
+func formatGitCommand(args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, "git")
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'`$\\") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,129 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestFormatGitCommand(t *testing.T) {
+	require.Equal(t, "git push origin v1.2.3", formatGitCommand([]string{"push", "origin", "v1.2.3"}))
+	require.Equal(t, `git tag --annotate --message "Release demo v1.2.3" v1.2.3`, formatGitCommand([]string{"tag", "--annotate", "--message", "Release demo v1.2.3", "v1.2.3"}))
+}
+
+func TestBumpTag_DryRun(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitTestFile(tempDIR, "test.txt")
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	headCommit := rese.C1(gcm.GitCommitHash("HEAD"))
+
+	config := &BumpConfig{
+		TagName:         "v0.0.1",
+		TagPrefix:       "v",
+		BumpLevel:       BumpMinor,
+		Annotate:        true,
+		UpdateChangelog: true,
+		DryRun:          true,
+		SkipGitPush:     true,
+	}
+	result, err := BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.False(t, result.Created)
+	require.Equal(t, ActionDryRun, result.Action)
+	require.Equal(t, "v0.1.0", result.NewTag)
+	require.NotNil(t, result.Plan)
+
+	// Nothing changes in dry-run mode
+	require.Equal(t, headCommit, rese.C1(gcm.GitCommitHash("HEAD")))
+	require.Equal(t, "v0.0.1", strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "tag", "--list")))))
+	require.NoFileExists(t, filepath.Join(tempDIR, "CHANGELOG.md"))
+
+	plan, err := planCreateAndPushTag(gcm, config, "v0.1.0", "")
+	require.NoError(t, err)
+	t.Log(plan.String())
+	require.Equal(t, "v0.0.1", plan.OldTag)
+	require.Equal(t, "v0.1.0", plan.NewTag)
+	require.Equal(t, headCommit, plan.TargetCommit)
+	require.Equal(t, "ok", plan.Preflight)
+	require.Equal(t, []string{
+		"# write new version into CHANGELOG.md",
+		"git add -- CHANGELOG.md",
+		`git commit --message "chore(release): v0.1.0" -- CHANGELOG.md`,
+		"git tag --annotate --message \"Release " + filepath.Base(tempDIR) + " v0.1.0\" v0.1.0",
+	}, plan.Commands)
+}
+
+func TestPlanCreateAndPushTag_TargetRef(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	baseCommit := rese.C1(gcm.GitCommitHash("HEAD"))
+	commitTestFile(tempDIR, "test.txt")
+
+	plan, err := planCreateAndPushTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v"}, "v0.0.2", baseCommit)
+	require.NoError(t, err)
+	require.Equal(t, baseCommit, plan.TargetCommit)
+	require.Equal(t, []string{"git tag v0.0.2 " + baseCommit, "git push origin v0.0.2"}, plan.Commands)
+
+	// A release commit cannot go below HEAD
+	_, err = planCreateAndPushTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", UpdateChangelog: true}, "v0.0.2", baseCommit)
+	require.Error(t, err)
+	require.NoFileExists(t, filepath.Join(tempDIR, "CHANGELOG.md"))
+}
+
+func TestBumpTag_DryRunPreflight(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitTestFile(tempDIR, "test.txt")
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "test.txt"), []byte("changed"), 0644))
+
+	gcm := gitgo.New(tempDIR)
+
+	// The dirty repo is reported in the plan, the dry-run still shows what would happen
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", DryRun: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, ActionDryRun, result.Action)
+	require.Equal(t, "v0.0.2", result.NewTag)
+	require.True(t, strings.HasPrefix(result.Plan.Preflight, "failed: "), result.Plan.Preflight)
+
+	result, err = BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", DryRun: true, AllowDirty: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "skipped (allow-dirty)", result.Plan.Preflight)
+
+	// The real run still refuses the dirty repo
+	_, err = BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrDirtyRepo)
+}
+
+func TestBumpTag_DryRunVersionFiles(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "version.go"), []byte("package demo\n\nconst Name = \"v0.0.1\"\n"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "Add version.go"))
+
+	gcm := gitgo.New(tempDIR)
+
+	// version.go has no Version constant, the dry-run fails like the real run would
+	_, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", VersionFiles: []VersionFile{rese.V1(ParseVersionFile("version.go"))}, DryRun: true, SkipGitPush: true})
+	require.Error(t, err)
+
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", VersionFiles: []VersionFile{rese.V1(ParseVersionFile("version.go:Name"))}, DryRun: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Contains(t, result.Plan.Commands, "# write new version into version.go")
+}
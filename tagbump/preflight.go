@@ -0,0 +1,96 @@
+package tagbump
+
+import (
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// checkRepoState verifies the repository is safe to tag before any tag is created or pushed
+// Refuses uncommitted changes, untracked files in the module DIR, and a target commit missing on remote
+// The remote check is skipped when SkipGitPush is set, all checks are skipped with AllowDirty
+//
+// checkRepoState 在创建或推送标签之前验证仓库可以安全地打标签
+// 拒绝未提交的修改、模块目录中的未跟踪文件，以及远程不存在的目标提交
+// 设置 SkipGitPush 时跳过远程检查，设置 AllowDirty 时跳过所有检查
+func checkRepoState(gcm *gitgo.Gcm, config *BumpConfig, targetCommit string) error {
+	if config.AllowDirty {
+		zaplog.LOG.Warn("SKIP-REPO-STATE-CHECK", zap.String("reason", "allow-dirty"))
+		return nil
+	}
+
+	// Refuse uncommitted changes of tracked files in the whole repo
+	// 拒绝整个仓库中已跟踪文件的未提交修改
+	changes, err := runGit(gcm, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if changes != "" {
+		return erero.WithMessagef(ErrDirtyRepo, "working tree has uncommitted changes (allow-dirty to override):\n%s", changes)
+	}
+
+	// Refuse untracked files in the module DIR, they would be missing in the tagged source
+	// 拒绝模块目录中的未跟踪文件，它们不会包含在打标签的源码中
+	modulePath := config.ModulePath
+	if modulePath == "" {
+		modulePath = "."
+	}
+	untracked, err := runGit(gcm, "status", "--porcelain", "--untracked-files=all", "--", modulePath)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if untracked != "" {
+		return erero.WithMessagef(ErrDirtyRepo, "module path ((%s)) has untracked files (allow-dirty to override):\n%s", modulePath, untracked)
+	}
+
+	// The tag stays local when push is skipped, so the commit needs not be on remote yet
+	// 跳过推送时标签留在本地，因此提交暂不需要存在于远程
+	if config.SkipGitPush {
+		return nil
+	}
+	return checkCommitPushed(gcm, config, targetCommit)
+}
+
+// preflightStatus runs checkRepoState for the dry-run plan, reporting the outcome instead of failing
+// Returns "ok", "skipped (allow-dirty)" or "failed: " with the reason
+//
+// preflightStatus 为演练计划运行 checkRepoState，报告结果而不是返回失败
+// 返回 "ok"、"skipped (allow-dirty)" 或带原因的 "failed: "
+func preflightStatus(gcm *gitgo.Gcm, config *BumpConfig, targetCommit string) string {
+	if config.AllowDirty {
+		return "skipped (allow-dirty)"
+	}
+	if err := checkRepoState(gcm, config, targetCommit); err != nil {
+		zaplog.LOG.Warn("DRY-RUN-PREFLIGHT-FAILED", zap.Error(err))
+		return "failed: " + err.Error()
+	}
+	return "ok"
+}
+
+// checkCommitPushed verifies the target commit exists on remote before pushing a tag to it
+// Uses the upstream branch when tagging HEAD, else any remote-tracking branch containing the commit
+//
+// checkCommitPushed 在推送指向目标提交的标签之前验证该提交已存在于远程
+// 标记 HEAD 时使用上游分支，否则使用任何包含该提交的远程跟踪分支
+func checkCommitPushed(gcm *gitgo.Gcm, config *BumpConfig, targetCommit string) error {
+	if config.TargetRef == "" {
+		if upstream, err := runGit(gcm, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}"); err == nil && upstream != "" {
+			if _, err := runGit(gcm, "merge-base", "--is-ancestor", targetCommit, upstream); err != nil {
+				return erero.WithMessagef(ErrDirtyRepo, "commit ((%s)) is not contained in upstream branch ((%s)), push it first (allow-dirty to override)", targetCommit, upstream)
+			}
+			return nil
+		}
+	}
+
+	branches, err := runGit(gcm, "branch", "--remotes", "--contains", targetCommit)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if strings.TrimSpace(branches) == "" {
+		return erero.WithMessagef(ErrDirtyRepo, "commit ((%s)) is not contained in any remote branch, push it first (allow-dirty to override)", targetCommit)
+	}
+	return nil
+}
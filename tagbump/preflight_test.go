@@ -0,0 +1,90 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestBumpTag_DirtyWorkingTree(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	commitTestFile(tempDIR, "test.txt")
+
+	config := &BumpConfig{
+		TagName:     "v0.0.1",
+		TagPrefix:   "v",
+		VersionBase: 100,
+		AutoConfirm: true,
+		SkipGitPush: true,
+	}
+
+	require.True(t, t.Run("Uncommitted Changes", func(t *testing.T) {
+		must.Done(os.WriteFile(filepath.Join(tempDIR, "test.txt"), []byte("changed"), 0644))
+		defer func() { rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "checkout", "--", "test.txt")) }()
+
+		_, err := BumpTag(gcm, config)
+		require.ErrorIs(t, err, ErrDirtyRepo)
+	}))
+
+	require.True(t, t.Run("Untracked Files", func(t *testing.T) {
+		untrackedPath := filepath.Join(tempDIR, "untracked.txt")
+		must.Done(os.WriteFile(untrackedPath, []byte("untracked"), 0644))
+		defer func() { must.Done(os.Remove(untrackedPath)) }()
+
+		_, err := BumpTag(gcm, config)
+		require.ErrorIs(t, err, ErrDirtyRepo)
+
+		// Allow dirty overrides the checks
+		allowConfig := *config
+		allowConfig.AllowDirty = true
+		result, err := BumpTag(gcm, &allowConfig)
+		require.NoError(t, err)
+		require.True(t, result.Created)
+	}))
+}
+
+func TestBumpTag_UnpushedCommit(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	remoteDIR := rese.V1(os.MkdirTemp("", "tagbump-remote-*"))
+	defer func() { must.Done(os.RemoveAll(remoteDIR)) }()
+	rese.V1(osexec.NewExecConfig().WithPath(remoteDIR).Exec("git", "init", "--bare"))
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "remote", "add", "origin", remoteDIR))
+	rese.V1(execConfig.Exec("git", "push", "-u", "origin", "HEAD"))
+	commitTestFile(tempDIR, "test.txt")
+
+	config := &BumpConfig{
+		TagName:     "v0.0.1",
+		TagPrefix:   "v",
+		VersionBase: 100,
+		AutoConfirm: true,
+	}
+
+	// HEAD is not on remote yet
+	_, err := BumpTag(gcm, config)
+	require.ErrorIs(t, err, ErrDirtyRepo)
+
+	// After pushing HEAD the tag is created and pushed
+	rese.V1(execConfig.Exec("git", "push", "origin", "HEAD"))
+	result, err := BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+	require.True(t, result.Pushed)
+	require.Equal(t, ActionPushed, result.Action)
+
+	remoteTags := rese.V1(osexec.NewExecConfig().WithPath(remoteDIR).Exec("git", "tag", "--list"))
+	require.Contains(t, string(remoteTags), "v0.0.2")
+}
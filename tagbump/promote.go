@@ -0,0 +1,221 @@
+package tagbump
+
+import (
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// PromoteMainTag promotes the latest main project pre-release tag to its final release
+// Finds the latest v{major}.{minor}.{patch}-{pre} tag and creates v{major}.{minor}.{patch}
+//
+// PromoteMainTag 将主项目最新的预发布标签晋升为正式版本
+// 查找最新的 v{major}.{minor}.{patch}-{pre} 标签并创建 v{major}.{minor}.{patch}
+func PromoteMainTag(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
+	// Log main project promote operation parameters
+	// 记录主项目晋升操作参数
+	zaplog.LOG.Debug("PROMOTE-MAIN-TAG", zap.Bool("promote-at-head", config.PromoteAtHead))
+
+	// Use standard 'v' prefix for main project tags, match pre-release tags only
+	// 主项目标签使用标准的 'v' 前缀，仅匹配预发布标签
+	tagPrefix := "v"
+	majorGlob, err := moduleMajorGlob(gcm, "", 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*-*"
+
+	return PromoteTagMatchRegexp(gcm, tagPrefix, tagRegexp, config)
+}
+
+// PromoteSubModuleTag promotes the latest submodule pre-release tag to its final release
+// Uses the same submodule path prefix as BumpSubModuleTag
+//
+// PromoteSubModuleTag 将子模块最新的预发布标签晋升为正式版本
+// 使用与 BumpSubModuleTag 相同的子模块路径前缀
+func PromoteSubModuleTag(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
+	// Log submodule promote operation parameters
+	// 记录子模块晋升操作参数
+	zaplog.LOG.Debug("PROMOTE-SUB-MODULE-TAG", zap.Bool("promote-at-head", config.PromoteAtHead))
+
+	// Resolve the submodule DIR from config ModulePath or the nearest go.mod
+	// 根据配置 ModulePath 或最近的 go.mod 解析子模块目录
+	subPath, err := subModulePath(gcm, config.ModulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Construct submodule-specific tag prefix, the parent prefix in a major subdirectory, match pre-release tags only
+	// 构建子模块特定标签前缀，主版本子目录使用父级前缀，仅匹配预发布标签
+	tagPrefix, major, err := subModuleTagPrefix(gcm, subPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	majorGlob, err := moduleMajorGlob(gcm, subPath, major)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*-*"
+
+	// Scope repo checks and tag messages to the submodule DIR, working on a copy of the config
+	// 将仓库检查和标签消息限定到子模块目录，在配置副本上操作
+	subConfig := *config
+	subConfig.ModulePath = subPath
+
+	return PromoteTagMatchRegexp(gcm, tagPrefix, tagRegexp, &subConfig)
+}
+
+// PromoteTagMatchRegexp promotes the latest pre-release tag matching the pattern
+// The config TagName and TagPrefix are filled on a copy, the caller's config stays unchanged
+//
+// PromoteTagMatchRegexp 晋升匹配模式的最新预发布标签
+// 配置中的 TagName 和 TagPrefix 填充在副本上，调用方的配置保持不变
+func PromoteTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, config *BumpConfig) (*BumpResult, error) {
+	// Log regexp matching parameters for debugging
+	// 记录正则匹配参数用于调试
+	zaplog.LOG.Debug("PROMOTE-MATCH-REGEXP-TAG", zap.String("tag-prefix", tagPrefix), zap.String("tag-regexp", tagRegexp))
+
+	// Find latest pre-release tag matching the pattern, by version precedence or by date
+	// 按版本优先级或日期查找匹配模式的最新预发布标签
+	tagName, err := latestPreReleaseTag(gcm, tagPrefix, tagRegexp, config.TagSort)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if tagName == "" {
+		return nil, erero.WithMessagef(ErrNoTag, "not match pre-release tag name with tag-prefix=((%s)) tag-regexp=((%s))", tagPrefix, tagRegexp)
+	}
+
+	return PromoteTag(gcm, config.withTag(tagName, tagPrefix))
+}
+
+// latestPreReleaseTag returns the latest pre-release tag matching the pattern, empty when none matches
+// Unlike latestTagMatchRegexp the date order keeps the pre-release even when its release exists
+// So promoting a promoted pre-release reports ErrTagExists, not ErrNotPreRelease
+//
+// latestPreReleaseTag 返回匹配模式的最新预发布标签，没有匹配时返回空
+// 与 latestTagMatchRegexp 不同，按日期排序时即使正式版本已存在也保留预发布标签
+// 因此晋升已晋升的预发布标签时报告 ErrTagExists，而不是 ErrNotPreRelease
+func latestPreReleaseTag(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, tagSort TagSort) (string, error) {
+	if tagSort == TagSortDate {
+		tagName, err := gcm.LatestGitTagMatchRegexp(tagRegexp)
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+		return tagName, nil
+	}
+	return latestTagMatchRegexp(gcm, tagPrefix, tagRegexp, tagSort)
+}
+
+// PromoteTag creates the final release tag of the pre-release tag in config TagName
+// v1.3.0-rc.2 becomes v1.3.0, tagged on the pre-release commit or on HEAD with PromoteAtHead
+// Goes through the same confirm/create/push flow as BumpTag
+//
+// PromoteTag 为配置 TagName 中的预发布标签创建正式版本标签
+// v1.3.0-rc.2 变为 v1.3.0，打在预发布提交上，或在 PromoteAtHead 时打在 HEAD 上
+// 与 BumpTag 使用相同的确认/创建/推送流程
+func PromoteTag(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
+	zaplog.SUG.Infoln("STARTING-PROMOTE-TAG", neatjsons.S(config))
+
+	// Strip the pre-release part to get the final release tag name
+	// 去除预发布部分得到正式版本标签名
+	version, err := Parse(config.TagName, config.TagPrefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if !version.IsPreRelease() {
+		zaplog.LOG.Error("NOT-PRE-RELEASE-TAG", zap.String("tag", config.TagName))
+		return nil, erero.WithMessagef(ErrNotPreRelease, "tag ((%s))", config.TagName)
+	}
+	newTagName := version.Release().String()
+	zaplog.LOG.Info("PROMOTE-TAG-NAME", zap.String("pre-release-tag", config.TagName), zap.String("release-tag", newTagName))
+
+	// Refuse to promote when the release tag exists already
+	// 当正式版本标签已存在时拒绝晋升
+	if commitHash, err := gcm.GitCommitHash(newTagName); err == nil && commitHash != "" {
+		return nil, erero.WithMessagef(ErrTagExists, "release tag ((%s))", newTagName)
+	}
+
+	// Refuse to promote an older pre-release after a higher final release, e.g. v1.3.0-rc.2 after v1.4.0
+	// 拒绝在更高的正式版本之后晋升较旧的预发布，例如 v1.4.0 之后的 v1.3.0-rc.2
+	releaseTagName, err := latestReleaseTag(gcm, config)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if releaseTagName != "" {
+		releaseVersion, err := Parse(releaseTagName, config.TagPrefix)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if version.Release().Compare(releaseVersion) <= 0 {
+			return nil, erero.WithMessagef(ErrVersionNotGreater, "release tag ((%s)) latest release tag ((%s))", newTagName, releaseTagName)
+		}
+	}
+
+	// Tag the pre-release commit by default, HEAD or the target ref when configured
+	// 默认标记预发布提交，配置后标记 HEAD 或目标引用
+	targetRef := config.TagName
+	switch {
+	case config.TargetRef != "":
+		targetRef = config.TargetRef
+	case config.PromoteAtHead:
+		targetRef = "HEAD"
+	}
+	targetCommit, err := resolveCommit(gcm, targetRef)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Refuse to tag a dirty working tree or a commit missing on remote, dry-run reports it in the plan instead
+	// 拒绝为有修改的工作区或远程不存在的提交打标签，演练模式改为在计划中报告
+	if !config.DryRun {
+		if err := checkRepoState(gcm, config, targetCommit); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	return createAndPushTag(gcm, config, newTagName, targetCommit)
+}
+
+// latestReleaseTag returns the highest final release tag of the config prefix, empty when there is none
+// Only the majors of the config module DIR count, like bump, so v2/go.mod tags do not block the parent
+//
+// latestReleaseTag 返回配置前缀中最高的正式版本标签，没有时返回空
+// 与 bump 一样只统计配置模块目录的主版本，因此 v2/go.mod 的标签不会阻止父级
+func latestReleaseTag(gcm *gitgo.Gcm, config *BumpConfig) (string, error) {
+	var major int
+	if config.ModulePath != "" {
+		_, subMajor, err := subModuleTagPrefix(gcm, config.ModulePath)
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+		major = subMajor
+	}
+	majorGlob, err := moduleMajorGlob(gcm, config.ModulePath, major)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	output, err := runGit(gcm, "tag", "--list", config.TagPrefix+majorGlob+".[0-9]*.[0-9]*")
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+
+	// Pick the highest version, skipping pre-releases and tags not in version format with this prefix
+	// 选择最高版本，跳过预发布标签和不符合该前缀版本格式的标签
+	var latestName string
+	var latest Version
+	for _, tagName := range strings.Split(output, "\n") {
+		version, err := Parse(tagName, config.TagPrefix)
+		if err != nil || version.IsPreRelease() {
+			continue
+		}
+		if latestName == "" || version.Compare(latest) > 0 {
+			latestName = tagName
+			latest = version
+		}
+	}
+	zaplog.LOG.Debug("LATEST-RELEASE-TAG", zap.String("tag-prefix", config.TagPrefix), zap.String("tag", latestName))
+	return latestName, nil
+}
@@ -0,0 +1,106 @@
+package tagbump
+
+import (
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestPromoteTag(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "v0.1.0-rc.2"))
+	commitTestFile(tempDIR, "test.txt")
+
+	config := &BumpConfig{
+		TagName:     "v0.1.0-rc.2",
+		TagPrefix:   "v",
+		AutoConfirm: true,
+		SkipGitPush: true,
+	}
+
+	result, err := PromoteTag(gcm, config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	// The release lands on the pre-release commit, not on HEAD
+	require.Equal(t, rese.C1(gcm.GitCommitHash("v0.1.0-rc.2")), rese.C1(gcm.GitCommitHash("v0.1.0")))
+
+	// Promoting again is refused since the release exists
+	_, err = PromoteTag(gcm, config)
+	require.Error(t, err)
+}
+
+func TestPromoteTag_AtHead(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "v0.1.0-rc.1"))
+	commitTestFile(tempDIR, "test.txt")
+
+	result, err := PromoteMainTag(gcm, &BumpConfig{PromoteAtHead: true, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("v0.1.0")))
+}
+
+func TestPromoteTag_NotPreRelease(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+
+	_, err := PromoteTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrNotPreRelease)
+}
+
+func TestPromoteTag_OlderThanRelease(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "v1.3.0-rc.2"))
+	commitTestFile(tempDIR, "test.txt")
+	rese.V1(execConfig.Exec("git", "tag", "v1.4.0"))
+
+	// v1.3.0 would go below the latest release v1.4.0
+	_, err := PromoteTag(gcm, &BumpConfig{TagName: "v1.3.0-rc.2", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrVersionNotGreater)
+	_, err = gcm.GitCommitHash("v1.3.0")
+	require.Error(t, err)
+
+	// A pre-release above the latest release is still promoted
+	rese.V1(execConfig.Exec("git", "tag", "v1.5.0-rc.1"))
+	result, err := PromoteTag(gcm, &BumpConfig{TagName: "v1.5.0-rc.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v1.5.0", result.NewTag)
+}
+
+func TestPromoteMainTag_SortDate(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "v0.1.0-rc.1"))
+
+	config := &BumpConfig{TagSort: TagSortDate, AutoConfirm: true, SkipGitPush: true}
+	result, err := PromoteMainTag(gcm, config)
+	require.NoError(t, err)
+	require.Equal(t, "v0.1.0-rc.1", result.OldTag)
+	require.Equal(t, "v0.1.0", result.NewTag)
+
+	// The promoted pre-release is still picked by date, the release exists already
+	_, err = PromoteMainTag(gcm, config)
+	require.ErrorIs(t, err, ErrTagExists)
+}
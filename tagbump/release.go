@@ -0,0 +1,320 @@
+package tagbump
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// ReleaseCommitMessage is the message template of the release commit, supports {module}, {version} and {tag}
+//
+// ReleaseCommitMessage 是发布提交的消息模板，支持 {module}、{version} 和 {tag}
+const ReleaseCommitMessage = "chore(release): {tag}"
+
+// changelogHeader starts a new CHANGELOG.md in Keep a Changelog style
+//
+// changelogHeader 以 Keep a Changelog 风格开始新的 CHANGELOG.md
+const changelogHeader = "# Changelog\n\nAll notable changes to this project will be documented in this file.\n"
+
+// releaseCommit records the release commit made before tagging, used to push or roll it back
+//
+// releaseCommit 记录打标签之前创建的发布提交，用于推送或回滚
+type releaseCommit struct {
+	baseCommit string // HEAD commit before the release commit // 发布提交之前的 HEAD 提交
+	branch     string // Branch holding the release commit // 包含发布提交的分支
+}
+
+// releaseFile is a file of the release commit with its new content
+//
+// releaseFile 是发布提交中的文件及其新内容
+type releaseFile struct {
+	name    string // Path relative to repo root // 相对于仓库根目录的路径
+	content []byte // New content // 新内容
+}
+
+// needsReleaseCommit tells whether files are written and committed before tagging
+//
+// needsReleaseCommit 判断打标签之前是否需要写入并提交文件
+func (config *BumpConfig) needsReleaseCommit() bool {
+	return config.UpdateChangelog || len(config.VersionFiles) > 0 || config.MigrateModule != ""
+}
+
+// createReleaseCommit writes the release files and commits them on the current branch
+// The new tag must go on HEAD, all files are rendered first and the written files are restored when the commit fails
+//
+// createReleaseCommit 写入发布文件并提交到当前分支
+// 新标签必须打在 HEAD 上，先渲染全部文件，提交失败时恢复已写入的文件
+func createReleaseCommit(gcm *gitgo.Gcm, config *BumpConfig, newTagName string, targetRef string) (*releaseCommit, error) {
+	headCommit, err := resolveCommit(gcm, "HEAD")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if targetRef != "" {
+		targetCommit, err := resolveCommit(gcm, targetRef)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if targetCommit != headCommit {
+			return nil, erero.Errorf("release commit needs the new tag on HEAD, target ((%s)) is not HEAD", targetRef)
+		}
+	}
+	branch, err := runGit(gcm, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return nil, erero.WithMessage(err, "release commit needs a branch, HEAD is detached")
+	}
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Render every file before writing any, so a render failure leaves the working tree untouched
+	// 在写入任何文件之前渲染全部文件，使渲染失败时工作区保持不变
+	files, err := renderReleaseFiles(gcm, config, newTagName, topPath, headCommit)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	message, err := releaseCommitMessage(gcm, config, newTagName)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Keep the original contents so a failed write or commit leaves the working tree untouched
+	// 保留原始内容，使写入或提交失败时工作区保持不变
+	backups := map[string][]byte{}
+	restore := func() {
+		for name, content := range backups {
+			fullPath := filepath.Join(topPath, name)
+			if content == nil {
+				_ = os.Remove(fullPath)
+			} else {
+				_ = os.WriteFile(fullPath, content, 0644)
+			}
+		}
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		fullPath := filepath.Join(topPath, file.name)
+		original, err := os.ReadFile(fullPath)
+		if err != nil && !os.IsNotExist(err) {
+			restore()
+			return nil, erero.Wro(err)
+		}
+		backups[file.name] = original
+		if err := os.WriteFile(fullPath, file.content, 0644); err != nil {
+			restore()
+			return nil, erero.Wro(err)
+		}
+		names = append(names, file.name)
+	}
+
+	// Commit only the release files, leaving other staged changes alone
+	// 仅提交发布文件，不影响其它已暂存的修改
+	zaplog.LOG.Info("CREATING-RELEASE-COMMIT", zap.String("tag", newTagName), zap.Strings("files", names))
+	if _, err := runGit(gcm, append([]string{"add", "--"}, names...)...); err != nil {
+		restore()
+		return nil, erero.Wro(err)
+	}
+	if _, err := runGit(gcm, append([]string{"commit", "--message", message, "--"}, names...)...); err != nil {
+		_, _ = runGit(gcm, append([]string{"reset", "--quiet", "--"}, names...)...)
+		restore()
+		return nil, erero.Wro(err)
+	}
+	zaplog.LOG.Info("SUCCESSFULLY-CREATED-RELEASE-COMMIT", zap.String("tag", newTagName), zap.String("branch", branch))
+	return &releaseCommit{baseCommit: headCommit, branch: branch}, nil
+}
+
+// rollback removes the new tag and the release commit, keeping local changes in the working tree
+//
+// rollback 删除新标签和发布提交，保留工作区中的本地修改
+func (release *releaseCommit) rollback(gcm *gitgo.Gcm, tagName string) {
+	zaplog.LOG.Warn("ROLLING-BACK-RELEASE", zap.String("tag", tagName), zap.String("base-commit", release.baseCommit))
+	if tagName != "" {
+		if _, err := runGit(gcm, "tag", "--delete", tagName); err != nil {
+			zaplog.LOG.Error("ROLLBACK-DELETE-TAG-FAILED", zap.String("tag", tagName), zap.Error(err))
+		}
+	}
+	if _, err := runGit(gcm, "reset", "--keep", release.baseCommit); err != nil {
+		zaplog.LOG.Error("ROLLBACK-RESET-FAILED", zap.String("base-commit", release.baseCommit), zap.Error(err))
+	}
+}
+
+// push pushes the release commit and the new tag in one atomic push, both or neither land on remote
+//
+// push 通过一次原子推送推送发布提交和新标签，两者要么都到达远程要么都不到达
+func (release *releaseCommit) push(gcm *gitgo.Gcm, tagName string) error {
+	args := releasePushArgs(gcm, release.branch, tagName)
+	zaplog.LOG.Info("PUSHING-RELEASE-ATOMIC", zap.Strings("args", args))
+	if _, err := runGit(gcm, args...); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// releasePushArgs returns the git args pushing the branch and the new tag atomically
+// Uses the branch upstream when configured, else the same branch name on origin
+//
+// releasePushArgs 返回原子推送分支和新标签的 git 参数
+// 配置了上游时使用上游分支，否则使用 origin 上的同名分支
+func releasePushArgs(gcm *gitgo.Gcm, branch string, tagName string) []string {
+	remote, err := runGit(gcm, "config", "--get", "branch."+branch+".remote")
+	if err != nil || remote == "" {
+		remote = "origin"
+	}
+	mergeRef, err := runGit(gcm, "config", "--get", "branch."+branch+".merge")
+	if err != nil || mergeRef == "" {
+		mergeRef = "refs/heads/" + branch
+	}
+	return []string{"push", "--atomic", remote, "HEAD:" + mergeRef, "refs/tags/" + tagName}
+}
+
+// releaseCommitMessage renders ReleaseCommitMessage with the module path and the new tag
+//
+// releaseCommitMessage 使用模块路径和新标签渲染 ReleaseCommitMessage
+func releaseCommitMessage(gcm *gitgo.Gcm, config *BumpConfig, newTagName string) (string, error) {
+	modulePath, err := moduleDisplayPath(gcm, config.ModulePath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return RenderTagMessage(ReleaseCommitMessage, modulePath, newTagName), nil
+}
+
+// releaseFileNames returns the files written in the release commit, relative to repo root
+//
+// releaseFileNames 返回发布提交中写入的文件，路径相对于仓库根目录
+func releaseFileNames(config *BumpConfig) []string {
+	var names []string
+	if config.UpdateChangelog {
+		names = append(names, filepath.ToSlash(filepath.Join(config.ModulePath, "CHANGELOG.md")))
+	}
+	for _, versionFile := range config.VersionFiles {
+		names = append(names, filepath.ToSlash(filepath.Join(config.ModulePath, versionFile.Path)))
+	}
+	return names
+}
+
+// renderReleaseFiles renders every file of the release commit without writing any of them
+// The module path migration goes first, then CHANGELOG.md, then the version files
+// A file rendered twice keeps one entry, version files see the migrated content
+//
+// renderReleaseFiles 渲染发布提交的全部文件但不写入任何文件
+// 先进行模块路径迁移，然后是 CHANGELOG.md，最后是版本文件
+// 渲染两次的文件只保留一项，版本文件基于迁移后的内容
+func renderReleaseFiles(gcm *gitgo.Gcm, config *BumpConfig, newTagName string, topPath string, headCommit string) ([]*releaseFile, error) {
+	var files []*releaseFile
+	find := func(name string) *releaseFile {
+		for _, file := range files {
+			if file.name == name {
+				return file
+			}
+		}
+		return nil
+	}
+	put := func(name string, content []byte) {
+		name = filepath.ToSlash(name)
+		if file := find(name); file != nil {
+			file.content = content
+			return
+		}
+		files = append(files, &releaseFile{name: name, content: content})
+	}
+
+	if config.MigrateModule != "" {
+		migrated, err := migrateModuleFiles(topPath, config)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		for _, file := range migrated {
+			put(file.name, file.content)
+		}
+	}
+
+	if config.UpdateChangelog {
+		name, content, err := renderChangelogFile(gcm, config, newTagName, topPath, headCommit)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		put(name, content)
+	}
+
+	// Rewrite the version files of the module, on top of the content rendered so far
+	// 在已渲染内容的基础上改写模块的版本文件
+	for _, versionFile := range config.VersionFiles {
+		name := filepath.Join(config.ModulePath, versionFile.Path)
+		var original []byte
+		if file := find(filepath.ToSlash(name)); file != nil {
+			original = file.content
+		} else {
+			content, err := os.ReadFile(filepath.Join(topPath, name))
+			if err != nil && !(os.IsNotExist(err) && versionFile.Kind == VersionFileText) {
+				return nil, erero.Wro(err)
+			}
+			original = content
+		}
+		content, err := versionFile.Rewrite(original, path.Base(newTagName))
+		if err != nil {
+			return nil, erero.WithMessagef(err, "cannot write version into ((%s))", name)
+		}
+		put(name, content)
+	}
+	return files, nil
+}
+
+// renderChangelogFile renders the module CHANGELOG.md with the new version section prepended
+// Returns the file path relative to repo root and the new content
+//
+// renderChangelogFile 渲染在开头加入新版本章节的模块 CHANGELOG.md
+// 返回相对于仓库根目录的文件路径和新内容
+func renderChangelogFile(gcm *gitgo.Gcm, config *BumpConfig, newTagName string, topPath string, headCommit string) (string, []byte, error) {
+	commits, err := ListCommits(gcm, config.TagName, headCommit, config.ModulePath)
+	if err != nil {
+		return "", nil, erero.Wro(err)
+	}
+	heading := path.Base(newTagName) + " - " + time.Now().Format(time.DateOnly)
+	section := RenderChangelog(heading, commits)
+
+	name := filepath.Join(config.ModulePath, "CHANGELOG.md")
+	original, err := os.ReadFile(filepath.Join(topPath, name))
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, erero.Wro(err)
+	}
+	return name, []byte(PrependChangelog(string(original), section)), nil
+}
+
+// PrependChangelog inserts a version section above the latest release of a Keep a Changelog document
+// The section goes below the title and an "Unreleased" section, a new document is created when empty
+//
+// PrependChangelog 将版本章节插入 Keep a Changelog 文档中最新发布之前
+// 章节位于标题和 "Unreleased" 章节之后，文档为空时创建新文档
+func PrependChangelog(document string, section string) string {
+	if strings.TrimSpace(document) == "" {
+		document = changelogHeader
+	}
+	section = strings.TrimRight(section, "\n") + "\n"
+
+	// Find the first "## " heading that is not the Unreleased section
+	// 查找第一个不是 Unreleased 章节的 "## " 标题
+	lines := strings.SplitAfter(document, "\n")
+	offset := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "## ") && !isUnreleasedHeading(line) {
+			return document[:offset] + section + "\n" + document[offset:]
+		}
+		offset += len(line)
+	}
+	return strings.TrimRight(document, "\n") + "\n\n" + section
+}
+
+// isUnreleasedHeading tells whether a "## " heading line is the Unreleased section
+//
+// isUnreleasedHeading 判断 "## " 标题行是否为 Unreleased 章节
+func isUnreleasedHeading(line string) bool {
+	title := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "## ")), "[]")
+	return strings.EqualFold(title, "Unreleased")
+}
@@ -0,0 +1,119 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestPrependChangelog(t *testing.T) {
+	section := "## v1.1.0 - 2025-01-02\n\n### Features\n\n- new thing\n"
+
+	document := PrependChangelog("", section)
+	require.Equal(t, changelogHeader+"\n"+section, document)
+
+	document = PrependChangelog(document, "## v1.2.0 - 2025-02-03\n\n### Bug Fixes\n\n- fixed\n")
+	require.Less(t, strings.Index(document, "## v1.2.0"), strings.Index(document, "## v1.1.0"))
+	require.True(t, strings.HasPrefix(document, changelogHeader))
+
+	// The release goes below the Unreleased section
+	document = PrependChangelog("# Changelog\n\n## [Unreleased]\n\n- wip\n\n## [1.0.0] - 2024-12-01\n\n- first\n", section)
+	require.Equal(t, "# Changelog\n\n## [Unreleased]\n\n- wip\n\n"+section+"\n## [1.0.0] - 2024-12-01\n\n- first\n", document)
+}
+
+func TestBumpTag_UpdateChangelog(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "main.go"), []byte("package main\n"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "feat: add main"))
+	baseCommit := rese.V1(execConfig.Exec("git", "rev-parse", "HEAD"))
+
+	gcm := gitgo.New(tempDIR)
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", UpdateChangelog: true, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	// The tag lands on the release commit made on top of the previous HEAD
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("v0.0.2")))
+	require.Equal(t, strings.TrimSpace(string(baseCommit)), rese.C1(gcm.GitCommitHash("HEAD~1")))
+	require.Equal(t, "chore(release): v0.0.2", strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "log", "-1", "--format=%s")))))
+
+	changelog := string(rese.V1(os.ReadFile(filepath.Join(tempDIR, "CHANGELOG.md"))))
+	t.Log(changelog)
+	require.Contains(t, changelog, "## v0.0.2 - ")
+	require.Contains(t, changelog, "- add main")
+	require.Empty(t, strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "status", "--porcelain")))))
+}
+
+func TestBumpTag_UpdateChangelogPush(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	remoteDIR := rese.V1(os.MkdirTemp("", "tagbump-remote-*"))
+	defer func() { must.Done(os.RemoveAll(remoteDIR)) }()
+	remoteExec := osexec.NewExecConfig().WithPath(remoteDIR)
+	rese.V1(remoteExec.Exec("git", "init", "--bare"))
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "remote", "add", "origin", remoteDIR))
+	rese.V1(execConfig.Exec("git", "push", "-u", "origin", "HEAD"))
+	commitTestFile(tempDIR, "test.txt")
+	rese.V1(execConfig.Exec("git", "push", "origin", "HEAD"))
+
+	// The remote has v0.0.2 already, the atomic push fails and everything is rolled back
+	rese.V1(execConfig.Exec("git", "push", "origin", "HEAD:refs/tags/v0.0.2"))
+	baseCommit := rese.C1(gcm.GitCommitHash("HEAD"))
+
+	config := &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", UpdateChangelog: true, AutoConfirm: true}
+	_, err := BumpTag(gcm, config)
+	require.ErrorIs(t, err, ErrPushFailed)
+	require.Equal(t, baseCommit, rese.C1(gcm.GitCommitHash("HEAD")))
+	require.NotContains(t, string(rese.V1(execConfig.Exec("git", "tag", "--list"))), "v0.0.2")
+	require.NoFileExists(t, filepath.Join(tempDIR, "CHANGELOG.md"))
+
+	// Once the remote tag is gone the release commit and the tag land on remote together
+	rese.V1(remoteExec.Exec("git", "tag", "--delete", "v0.0.2"))
+	result, err := BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+	require.True(t, result.Pushed)
+	require.Equal(t, ActionPushed, result.Action)
+
+	branch := strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "symbolic-ref", "--short", "HEAD"))))
+	remoteHead := strings.TrimSpace(string(rese.V1(remoteExec.Exec("git", "rev-parse", "refs/heads/"+branch))))
+	remoteTag := strings.TrimSpace(string(rese.V1(remoteExec.Exec("git", "rev-parse", "v0.0.2^{commit}"))))
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), remoteHead)
+	require.Equal(t, remoteHead, remoteTag)
+}
+
+func TestCreateReleaseCommit_RenderFailure(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitModuleSource(tempDIR, "", "example.com/demo")
+	rese.V1(execConfig.Exec("git", "tag", "v1.0.0"))
+
+	// CHANGELOG.md cannot be read as a file, git ignores the empty DIR so the repo stays clean
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, "CHANGELOG.md"), 0755))
+
+	gcm := gitgo.New(tempDIR)
+	_, err := MigrateMajorMainTag(gcm, &BumpConfig{UpdateChangelog: true, AutoConfirm: true, SkipGitPush: true})
+	require.Error(t, err)
+
+	// Nothing is written since the migration files are rendered along with the changelog
+	require.Equal(t, "example.com/demo", rese.C1(readModulePath(tempDIR)))
+	require.Empty(t, strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "status", "--porcelain")))))
+	require.NotContains(t, string(rese.V1(execConfig.Exec("git", "tag", "--list"))), "v2.0.0")
+}
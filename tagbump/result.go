@@ -0,0 +1,54 @@
+package tagbump
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BumpAction tells what a bump or promote finally did
+//
+// BumpAction 表示升级或晋升最终执行的操作
+type BumpAction string
+
+const (
+	ActionPushed         BumpAction = "pushed"          // New tag created and pushed // 新标签已创建并推送
+	ActionPushedOldTag   BumpAction = "pushed-old-tag"  // Old tag already on the target commit, pushed again // 旧标签已在目标提交上，重新推送
+	ActionSkippedPush    BumpAction = "skipped-push"    // Push skipped with SkipGitPush // 因 SkipGitPush 跳过推送
+	ActionDeclinedPush   BumpAction = "declined-push"   // User declined pushing the tag // 用户拒绝推送标签
+	ActionDeclinedCreate BumpAction = "declined-create" // User declined creating the new tag, nothing changed // 用户拒绝创建新标签，未做任何修改
+	ActionDryRun         BumpAction = "dry-run"         // Plan printed, nothing changed // 已打印计划，未做任何修改
+)
+
+// BumpResult reports exactly what a bump or promote did
+//
+// BumpResult 准确报告升级或晋升执行的操作
+type BumpResult struct {
+	OldTag     string     `json:"old_tag"`        // Tag bumped from // 作为升级起点的标签
+	NewTag     string     `json:"new_tag"`        // New tag, empty when the old tag is already on the target commit // 新标签，旧标签已在目标提交上时为空
+	CommitHash string     `json:"commit_hash"`    // Commit the tag points to // 标签指向的提交
+	Created    bool       `json:"created"`        // New tag created in local repo // 已在本地仓库创建新标签
+	Pushed     bool       `json:"pushed"`         // Tag pushed to remote // 标签已推送到远程
+	Action     BumpAction `json:"action"`         // Final action taken // 最终执行的操作
+	Plan       *BumpPlan  `json:"plan,omitempty"` // Plan of the dry-run, nil otherwise // 演练的计划，其它情况为 nil
+}
+
+// Declined tells whether the user declined at a prompt, leaving the tag not pushed
+//
+// Declined 判断用户是否在提示时拒绝，导致标签未推送
+func (result *BumpResult) Declined() bool {
+	return result.Action == ActionDeclinedCreate || result.Action == ActionDeclinedPush
+}
+
+// String renders the result as readable lines
+//
+// String 将结果渲染为可读的文本行
+func (result *BumpResult) String() string {
+	var sb strings.Builder
+	sb.WriteString("old-tag:     " + result.OldTag + "\n")
+	sb.WriteString("new-tag:     " + result.NewTag + "\n")
+	sb.WriteString("commit-hash: " + result.CommitHash + "\n")
+	sb.WriteString("created:     " + strconv.FormatBool(result.Created) + "\n")
+	sb.WriteString("pushed:      " + strconv.FormatBool(result.Pushed) + "\n")
+	sb.WriteString("action:      " + string(result.Action))
+	return sb.String()
+}
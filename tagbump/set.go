@@ -0,0 +1,179 @@
+package tagbump
+
+import (
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// SetMainTag creates the given version as the next main project tag
+// The version must be greater than the latest v{major}.{minor}.{patch} tag
+//
+// SetMainTag 将给定版本创建为主项目的下一个标签
+// 版本必须大于最新的 v{major}.{minor}.{patch} 标签
+func SetMainTag(gcm *gitgo.Gcm, version string, config *BumpConfig) (*BumpResult, error) {
+	// Log main project set operation parameters
+	// 记录主项目设置操作参数
+	zaplog.LOG.Debug("SET-MAIN-TAG", zap.String("version", version))
+
+	// Use standard 'v' prefix for main project tags
+	// 主项目标签使用标准的 'v' 前缀
+	tagPrefix := "v"
+	majorGlob, err := moduleMajorGlob(gcm, "", 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+
+	return SetTagMatchRegexp(gcm, tagPrefix, tagRegexp, version, config)
+}
+
+// SetSubModuleTag creates the given version as the next submodule tag
+// Uses the same submodule path prefix as BumpSubModuleTag
+//
+// SetSubModuleTag 将给定版本创建为子模块的下一个标签
+// 使用与 BumpSubModuleTag 相同的子模块路径前缀
+func SetSubModuleTag(gcm *gitgo.Gcm, version string, config *BumpConfig) (*BumpResult, error) {
+	// Log submodule set operation parameters
+	// 记录子模块设置操作参数
+	zaplog.LOG.Debug("SET-SUB-MODULE-TAG", zap.String("version", version))
+
+	// Resolve the submodule DIR from config ModulePath or the nearest go.mod
+	// 根据配置 ModulePath 或最近的 go.mod 解析子模块目录
+	subPath, err := subModulePath(gcm, config.ModulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Construct submodule-specific tag prefix with path, the parent prefix in a major subdirectory
+	// 构建带路径的子模块特定标签前缀，主版本子目录使用父级前缀
+	tagPrefix, major, err := subModuleTagPrefix(gcm, subPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	majorGlob, err := moduleMajorGlob(gcm, subPath, major)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
+
+	// Scope repo checks and tag messages to the submodule DIR, working on a copy of the config
+	// 将仓库检查和标签消息限定到子模块目录，在配置副本上操作
+	subConfig := *config
+	subConfig.ModulePath = subPath
+
+	return SetTagMatchRegexp(gcm, tagPrefix, tagRegexp, version, &subConfig)
+}
+
+// SetTagMatchRegexp creates the given version after the latest tag matching the pattern, or as the first tag without one
+// The config TagName and TagPrefix are filled on a copy, the caller's config stays unchanged
+//
+// SetTagMatchRegexp 在匹配模式的最新标签之后创建给定版本，没有匹配标签时作为首个标签创建
+// 配置中的 TagName 和 TagPrefix 填充在副本上，调用方的配置保持不变
+func SetTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, version string, config *BumpConfig) (*BumpResult, error) {
+	// Log regexp matching parameters for debugging
+	// 记录正则匹配参数用于调试
+	zaplog.LOG.Debug("SET-MATCH-REGEXP-TAG", zap.String("tag-prefix", tagPrefix), zap.String("tag-regexp", tagRegexp))
+
+	// Find latest tag matching the pattern, by version precedence or by date, reachable from the target ref when configured
+	// 按版本优先级或日期查找匹配模式的最新标签，配置目标引用时只查找从该引用可达的标签
+	tagName, err := latestMergedTagMatchRegexp(gcm, tagPrefix, tagRegexp, config.TagSort, config.TargetRef)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if tagName == "" {
+		// No tag of the prefix yet, the version starts it like init does
+		// 该前缀尚无标签，该版本像 init 一样作为起始标签
+		zaplog.LOG.Debug("SET-TAG-WITHOUT-LATEST", zap.String("tag-prefix", tagPrefix), zap.String("tag-regexp", tagRegexp))
+	}
+
+	return SetTag(gcm, version, config.withTag(tagName, tagPrefix))
+}
+
+// SetTag creates the given version as the tag after the latest tag in config TagName
+// Accepts "1.2.3", "v1.2.3" or the full tag name with the prefix, e.g. "sub/v1.2.3"
+// Refuses versions not greater than the latest tag and existing tags, an empty TagName skips the comparison,
+// then goes through the same confirm/create/push flow as BumpTag
+//
+// SetTag 将给定版本创建为配置 TagName 中最新标签之后的标签
+// 接受 "1.2.3"、"v1.2.3" 或带前缀的完整标签名，例如 "sub/v1.2.3"
+// 拒绝不大于最新标签的版本和已存在的标签，TagName 为空时跳过比较，
+// 然后与 BumpTag 使用相同的确认/创建/推送流程
+func SetTag(gcm *gitgo.Gcm, version string, config *BumpConfig) (*BumpResult, error) {
+	zaplog.SUG.Infoln("STARTING-SET-TAG", version, neatjsons.S(config))
+
+	// Parse the requested version with the prefix of the latest tag
+	// 使用最新标签的前缀解析请求的版本
+	newVersion, err := parseSetVersion(version, config.TagPrefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	newTagName := newVersion.String()
+	zaplog.LOG.Info("SET-TAG-NAME", zap.String("old-tag", config.TagName), zap.String("new-tag", newTagName))
+
+	// Refuse to go backwards, there is nothing to compare when the prefix has no tag yet
+	// 拒绝版本回退，前缀尚无标签时无需比较
+	if config.TagName != "" {
+		oldVersion, err := Parse(config.TagName, config.TagPrefix)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if newVersion.Compare(oldVersion) <= 0 {
+			return nil, erero.WithMessagef(ErrVersionNotGreater, "new version ((%s)) old version ((%s))", newTagName, config.TagName)
+		}
+	}
+	return createVersionTag(gcm, config, newTagName)
+}
+
+// createVersionTag creates the new tag on HEAD or the target ref, refusing existing tags and dirty repos
+// Shared by set and init, which compute the new tag name without bumping
+//
+// createVersionTag 在 HEAD 或目标引用上创建新标签，拒绝已存在的标签和有修改的仓库
+// 由 set 和 init 共享，它们不经升级直接计算新标签名
+func createVersionTag(gcm *gitgo.Gcm, config *BumpConfig, newTagName string) (*BumpResult, error) {
+	if commitHash, err := gcm.GitCommitHash(newTagName); err == nil && commitHash != "" {
+		return nil, erero.WithMessagef(ErrTagExists, "tag ((%s))", newTagName)
+	}
+
+	// Tag HEAD by default, or the target ref when configured
+	// 默认标记 HEAD，配置后标记目标引用
+	targetCommit, err := resolveCommit(gcm, config.targetRef())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Refuse to tag a dirty working tree or a commit missing on remote, dry-run reports it in the plan instead
+	// 拒绝为有修改的工作区或远程不存在的提交打标签，演练模式改为在计划中报告
+	if !config.DryRun {
+		if err := checkRepoState(gcm, config, targetCommit); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+
+	var targetRef string
+	if config.TargetRef != "" {
+		targetRef = targetCommit
+	}
+	return createAndPushTag(gcm, config, newTagName, targetRef)
+}
+
+// parseSetVersion parses the requested version, with or without the tag prefix
+//
+// parseSetVersion 解析请求的版本，可带或不带标签前缀
+func parseSetVersion(version string, tagPrefix string) (Version, error) {
+	// Drop the full prefix, or the trailing "v" of the prefix, then put the full prefix back
+	// 去掉完整前缀或前缀末尾的 "v"，然后加回完整前缀
+	versionText := strings.TrimPrefix(version, tagPrefix)
+	if versionText == version {
+		versionText = strings.TrimPrefix(version, "v")
+	}
+	newVersion, err := Parse(tagPrefix+versionText, tagPrefix)
+	if err != nil {
+		return Version{}, erero.WithMessagef(ErrTagFormat, "version ((%s)) with tag-prefix=((%s))", version, tagPrefix)
+	}
+	return newVersion, nil
+}
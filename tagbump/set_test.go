@@ -0,0 +1,75 @@
+package tagbump
+
+import (
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+func TestSetTag(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	commitTestFile(tempDIR, "test.txt")
+
+	config := &BumpConfig{AutoConfirm: true, SkipGitPush: true}
+
+	result, err := SetMainTag(gcm, "1.0.0", config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+	require.Equal(t, "v0.0.1", result.OldTag)
+	require.Equal(t, "v1.0.0", result.NewTag)
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("v1.0.0")))
+
+	// Lower and equal versions are refused
+	_, err = SetMainTag(gcm, "v0.9.0", config)
+	require.ErrorIs(t, err, ErrVersionNotGreater)
+	_, err = SetMainTag(gcm, "v1.0.0", config)
+	require.ErrorIs(t, err, ErrVersionNotGreater)
+
+	// Wrong version format is refused
+	_, err = SetMainTag(gcm, "v2.0", config)
+	require.ErrorIs(t, err, ErrTagFormat)
+}
+
+func TestSetTag_Exists(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	commitTestFile(tempDIR, "test.txt")
+
+	// The old tag stays v0.0.1, so only the exists check catches the duplicate
+	_, err := SetTag(gcm, "v2.0.0", &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	_, err = SetTag(gcm, "v2.0.0", &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrTagExists)
+}
+
+func TestSetSubModuleTag_Untagged(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	commitGoMod(tempDIR, "sub", "example.com/demo/sub")
+
+	// Without a tag of the prefix the version starts it
+	result, err := SetSubModuleTag(gcm, "v1.0.0", &BumpConfig{ModulePath: "sub", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+	require.Equal(t, "", result.OldTag)
+	require.Equal(t, "sub/v1.0.0", result.NewTag)
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("sub/v1.0.0")))
+}
+
+func TestParseSetVersion(t *testing.T) {
+	require.Equal(t, "sub/v1.2.3", rese.V1(parseSetVersion("1.2.3", "sub/v")).String())
+	require.Equal(t, "sub/v1.2.3", rese.V1(parseSetVersion("v1.2.3", "sub/v")).String())
+	require.Equal(t, "sub/v1.2.3-rc.1", rese.V1(parseSetVersion("sub/v1.2.3-rc.1", "sub/v")).String())
+
+	_, err := parseSetVersion("other/v1.2.3", "sub/v")
+	require.ErrorIs(t, err, ErrTagFormat)
+}
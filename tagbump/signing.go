@@ -0,0 +1,110 @@
+package tagbump
+
+import (
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// SignatureStatus describes the signature state of a tag
+//
+// SignatureStatus 描述标签的签名状态
+type SignatureStatus string
+
+const (
+	SignatureLightweight SignatureStatus = "lightweight" // Lightweight tag, cannot carry a signature // 轻量标签，无法携带签名
+	SignatureUnsigned    SignatureStatus = "unsigned"    // Annotated tag without signature // 没有签名的附注标签
+	SignatureGood        SignatureStatus = "good"        // Signature verified by git // 签名通过 git 验证
+	SignatureBad         SignatureStatus = "bad"         // Signature present but verification failed // 存在签名但验证失败
+)
+
+// TagSignature holds the signature report of a tag
+//
+// TagSignature 保存标签的签名报告
+type TagSignature struct {
+	TagName string          `json:"tag"`    // Tag name // 标签名
+	Status  SignatureStatus `json:"status"` // Signature status // 签名状态
+	Format  string          `json:"format"` // Signature format: openpgp/ssh/x509, empty when unsigned // 签名格式：openpgp/ssh/x509，未签名时为空
+	Output  string          `json:"output"` // Output of git verify-tag // git verify-tag 的输出
+}
+
+// VerifyTag reports the signature status of an existing tag
+// Signed tags are checked with git verify-tag, using gpg.format and gpg.ssh.allowedSignersFile from git config
+//
+// VerifyTag 报告已有标签的签名状态
+// 已签名的标签使用 git verify-tag 检查，使用 git 配置中的 gpg.format 和 gpg.ssh.allowedSignersFile
+func VerifyTag(gcm *gitgo.Gcm, tagName string) (*TagSignature, error) {
+	tagRef := "refs/tags/" + tagName
+	objectType, err := runGit(gcm, "cat-file", "-t", tagRef)
+	if err != nil {
+		return nil, erero.Wrapf(err, "tag ((%s)) does not exist", tagName)
+	}
+	if objectType != "tag" {
+		return &TagSignature{TagName: tagName, Status: SignatureLightweight}, nil
+	}
+
+	signature, err := runGit(gcm, "for-each-ref", "--format=%(contents:signature)", tagRef)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if signature == "" {
+		return &TagSignature{TagName: tagName, Status: SignatureUnsigned}, nil
+	}
+
+	topPath, err := gcm.GetTopPath()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	// Keep the output on failure, it tells why the signature is bad
+	// 失败时保留输出，其中说明了签名无效的原因
+	output, err := osexec.NewExecConfig().WithPath(topPath).Exec("git", "verify-tag", tagRef)
+	result := &TagSignature{
+		TagName: tagName,
+		Status:  SignatureGood,
+		Format:  signatureFormat(signature),
+		Output:  strings.TrimSpace(string(output)),
+	}
+	if err != nil {
+		zaplog.LOG.Debug("VERIFY-TAG-FAILED", zap.String("tag", tagName), zap.Error(err))
+		result.Status = SignatureBad
+	}
+	return result, nil
+}
+
+// signatureFormat detects the signature format from the armored signature block
+//
+// signatureFormat 根据签名块的头部检测签名格式
+func signatureFormat(signature string) string {
+	switch {
+	case strings.Contains(signature, "BEGIN SSH SIGNATURE"):
+		return "ssh"
+	case strings.Contains(signature, "BEGIN SIGNED MESSAGE"):
+		return "x509"
+	case strings.Contains(signature, "BEGIN PGP SIGNATURE"):
+		return "openpgp"
+	default:
+		return "unknown"
+	}
+}
+
+// shouldSignTag tells whether the new tag is signed
+// Sign or SigningKey request it, otherwise tag.gpgSign of git config decides
+//
+// shouldSignTag 判断新标签是否需要签名
+// 设置 Sign 或 SigningKey 时签名，否则由 git 配置的 tag.gpgSign 决定
+func shouldSignTag(gcm *gitgo.Gcm, config *BumpConfig) bool {
+	if config.Sign || config.SigningKey != "" {
+		return true
+	}
+	// git config exits with 1 when the key is not set, which means not signing
+	// 未设置该键时 git config 以 1 退出，表示不签名
+	gpgSign, err := runGit(gcm, "config", "--bool", "--get", "tag.gpgSign")
+	if err != nil {
+		return false
+	}
+	return gpgSign == "true"
+}
@@ -0,0 +1,104 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+// setupSSHSigning creates a throwaway SSH key and configures the test repo to sign tags with it
+// Returns the private key path, the key is trusted through gpg.ssh.allowedSignersFile
+func setupSSHSigning(t *testing.T, tempDIR string) string {
+	keyDIR := t.TempDir()
+	keyPath := filepath.Join(keyDIR, "signing-key")
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+
+	rese.V1(execConfig.Exec("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test@example.com", "-f", keyPath))
+	publicKey := rese.V1(os.ReadFile(keyPath + ".pub"))
+	allowedSigners := filepath.Join(keyDIR, "allowed-signers")
+	must.Done(os.WriteFile(allowedSigners, append([]byte("test@example.com "), publicKey...), 0644))
+
+	rese.V1(execConfig.Exec("git", "config", "gpg.format", "ssh"))
+	rese.V1(execConfig.Exec("git", "config", "user.signingkey", keyPath))
+	rese.V1(execConfig.Exec("git", "config", "gpg.ssh.allowedSignersFile", allowedSigners))
+	return keyPath
+}
+
+func TestBumpTag_Sign(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	setupSSHSigning(t, tempDIR)
+	commitTestFile(tempDIR, "test.txt")
+
+	gcm := gitgo.New(tempDIR)
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", Sign: true, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	signature, err := VerifyTag(gcm, "v0.0.2")
+	require.NoError(t, err)
+	require.Equal(t, SignatureGood, signature.Status)
+	require.Equal(t, "ssh", signature.Format)
+
+	// The initial tag is lightweight
+	signature, err = VerifyTag(gcm, "v0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, SignatureLightweight, signature.Status)
+
+	_, err = VerifyTag(gcm, "v9.9.9")
+	require.Error(t, err)
+}
+
+func TestBumpTag_SignByGitConfig(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	setupSSHSigning(t, tempDIR)
+	commitTestFile(tempDIR, "test.txt")
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "config", "tag.gpgSign", "true"))
+
+	gcm := gitgo.New(tempDIR)
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	signature, err := VerifyTag(gcm, "v0.0.2")
+	require.NoError(t, err)
+	require.Equal(t, SignatureGood, signature.Status)
+}
+
+func TestVerifyTag_Status(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	setupSSHSigning(t, tempDIR)
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+
+	rese.V1(execConfig.Exec("git", "tag", "--annotate", "--message", "not signed", "v0.0.2"))
+	signature, err := VerifyTag(gcm, "v0.0.2")
+	require.NoError(t, err)
+	require.Equal(t, SignatureUnsigned, signature.Status)
+
+	// A key missing in the allowed signers file gives a bad signature
+	otherKey := filepath.Join(t.TempDir(), "other-key")
+	rese.V1(execConfig.Exec("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", otherKey))
+	commitTestFile(tempDIR, "test.txt")
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.2", TagPrefix: "v", SigningKey: otherKey, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	signature, err = VerifyTag(gcm, "v0.0.3")
+	require.NoError(t, err)
+	require.Equal(t, SignatureBad, signature.Status)
+	require.Equal(t, "ssh", signature.Format)
+}
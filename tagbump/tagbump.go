@@ -9,136 +9,197 @@ package tagbump
 
 import (
 	"fmt"
-	"path/filepath"
-	"regexp"
-	"strconv"
+	"io"
+	"os"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/go-xlan/gitgo"
-	"github.com/yyle88/done"
 	"github.com/yyle88/erero"
-	"github.com/yyle88/must/mustnum"
 	"github.com/yyle88/neatjson/neatjsons"
-	"github.com/yyle88/rese"
 	"github.com/yyle88/zaplog"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 )
 
-// BumpGitTag bumps the latest Git tag version with version base support
-// Retrieves the most recent tag and increments its version using specified base system
-// Returns success status and handles cases where no tags exist
+// BumpGitTagWithConfig bumps the latest Git tag version with version base support
+// Retrieves the most recent tag and increments its version using the config bump level and base system
+// Returns the bump result and handles cases where no tags exist
 //
-// BumpGitTag 使用版本基数支持升级最新的 Git 标签版本
-// 获取最新标签并使用指定基数系统递增其版本
-// 返回成功状态并处理不存在标签的情况
-func BumpGitTag(gcm *gitgo.Gcm, versionBase int) (bool, error) {
+// BumpGitTagWithConfig 使用版本基数支持升级最新的 Git 标签版本
+// 获取最新标签并使用配置的升级级别和基数系统递增其版本
+// 返回升级结果并处理不存在标签的情况
+func BumpGitTagWithConfig(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
 	// Log operation parameters for debugging
 	// 记录操作参数用于调试
-	zaplog.LOG.Debug("BUMP-GIT-TAG", zap.Int("version-base", versionBase))
+	zaplog.LOG.Debug("BUMP-GIT-TAG", zap.Int("version-base", config.VersionBase), zap.String("bump-level", string(config.BumpLevel)))
 
-	// Retrieve the latest Git tag from repository
-	// 从仓库获取最新的 Git 标签
-	tagName, err := gcm.LatestGitTag()
+	// Retrieve the latest main project Git tag, by version precedence or by date
+	// Majors of major subdirectories (v2/go.mod) belong to those modules and are left out
+	// 按版本优先级或日期获取最新的主项目 Git 标签
+	// 主版本子目录（v2/go.mod）的主版本属于那些模块，不计入
+	majorGlob, err := moduleMajorGlob(gcm, "", 0)
 	if err != nil {
-		return false, erero.Wro(err)
+		return nil, erero.Wro(err)
+	}
+	// With a target ref only the tags reachable from it count, e.g. a patch on a release branch
+	// 配置目标引用时只统计从该引用可达的标签，例如在发布分支上打补丁
+	tagName, err := latestMergedTagMatchRegexp(gcm, "v", "v"+majorGlob+".[0-9]*.[0-9]*", config.TagSort, config.TargetRef)
+	if err != nil {
+		return nil, erero.Wro(err)
 	}
 
-	// Validate that at least one tag exists
-	// 验证至少存在一个标签
+	// Validate that at least one tag exists, or create the initial tag when configured
+	// 验证至少存在一个标签，配置了初始版本时创建初始标签
 	if tagName == "" {
-		return false, erero.New("no tag")
+		if config.InitialVersion != "" {
+			return InitTag(gcm, config.InitialVersion, config.withTag("", "v"))
+		}
+		return nil, erero.WithMessagef(ErrNoTag, "tag-prefix=((%s))", "v")
 	}
 
 	// Delegate to core version bumping logic
 	// 委托给核心版本升级逻辑
-	return BumpTagNum(gcm, tagName, "v", versionBase)
+	return BumpTag(gcm, config.withTag(tagName, "v"))
 }
 
-// BumpSubModuleTag bumps Git tag version for submodule with path prefix
+// BumpSubModuleTagWithConfig bumps Git tag version for submodule with path prefix
 // Constructs submodule-specific tag prefix and applies version bumping logic
 // Ensures operation is performed within a valid submodule context
 //
-// BumpSubModuleTag 使用路径前缀升级子模块的 Git 标签版本
+// BumpSubModuleTagWithConfig 使用路径前缀升级子模块的 Git 标签版本
 // 构造子模块特定的标签前缀并应用版本升级逻辑
 // 确保操作在有效的子模块上下文中执行
-func BumpSubModuleTag(gcm *gitgo.Gcm, versionBase int) (bool, error) {
+func BumpSubModuleTagWithConfig(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
 	// Log submodule tag operation parameters
 	// 记录子模块标签操作参数
-	zaplog.LOG.Debug("BUMP-SUB-MODULE-TAG", zap.Int("version-base", versionBase))
+	zaplog.LOG.Debug("BUMP-SUB-MODULE-TAG", zap.Int("version-base", config.VersionBase), zap.String("bump-level", string(config.BumpLevel)))
 
-	// Get current submodule path relative to main project
-	// 获取相对于主项目的当前子模块路径
-	subPath, err := gcm.GetSubPath()
+	// Resolve the submodule DIR from config ModulePath or the nearest go.mod
+	// 根据配置 ModulePath 或最近的 go.mod 解析子模块目录
+	subPath, err := subModulePath(gcm, config.ModulePath)
 	if err != nil {
-		return false, erero.Wro(err)
+		return nil, erero.Wro(err)
 	}
 
-	// Ensure we are inside a submodule DIR
-	// 确保我们在子模块目录内
-	if subPath == "" {
-		return false, erero.New("not in sub-module path")
+	// Construct submodule-specific tag prefix with path, the parent prefix in a major subdirectory
+	// 构建带路径的子模块特定标签前缀，主版本子目录使用父级前缀
+	tagPrefix, major, err := subModuleTagPrefix(gcm, subPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	majorGlob, err := moduleMajorGlob(gcm, subPath, major)
+	if err != nil {
+		return nil, erero.Wro(err)
 	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
 
-	// Construct submodule-specific tag prefix with path
-	// 构建带路径的子模块特定标签前缀
-	tagPrefix := filepath.Join(subPath, "v")
-	tagRegexp := tagPrefix + "[0-9]*.[0-9]*.[0-9]*"
+	// Scope commits to the submodule DIR, working on a copy of the config
+	// 将提交范围限定到子模块目录，在配置副本上操作
+	subConfig := *config
+	subConfig.ModulePath = subPath
 
 	// Apply regexp-based tag matching and bumping
 	// 应用基于正则表达式的标签匹配和升级
-	return BumpTagMatchRegexp(gcm, tagPrefix, tagRegexp, versionBase)
+	return BumpTagMatchRegexpWithConfig(gcm, tagPrefix, tagRegexp, &subConfig)
 }
 
-// BumpMainTag bumps Git tag version for main project repository
+// BumpMainTagWithConfig bumps Git tag version for main project repository
 // Uses standard 'v' prefix for main project tags and applies semantic versioning
 // Designed for main project root DIR operations
 //
-// BumpMainTag 升级主项目仓库的 Git 标签版本
+// BumpMainTagWithConfig 升级主项目仓库的 Git 标签版本
 // 使用标准的 'v' 前缀用于主项目标签并应用语义版本控制
 // 设计用于主项目根目录操作
-func BumpMainTag(gcm *gitgo.Gcm, versionBase int) (bool, error) {
+func BumpMainTagWithConfig(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
 	// Log main project tag operation parameters
 	// 记录主项目标签操作参数
-	zaplog.LOG.Debug("BUMP-MAIN-TAG", zap.Int("version-base", versionBase))
+	zaplog.LOG.Debug("BUMP-MAIN-TAG", zap.Int("version-base", config.VersionBase), zap.String("bump-level", string(config.BumpLevel)))
 
 	// Use standard 'v' prefix for main project tags
 	// 主项目标签使用标准的 'v' 前缀
 	tagPrefix := "v"
-	tagRegexp := tagPrefix + "[0-9]*.[0-9]*.[0-9]*"
+	majorGlob, err := moduleMajorGlob(gcm, "", 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tagRegexp := tagPrefix + majorGlob + ".[0-9]*.[0-9]*"
 
 	// Apply regexp-based tag matching and bumping for main project
 	// 为主项目应用基于正则表达式的标签匹配和升级
-	return BumpTagMatchRegexp(gcm, tagPrefix, tagRegexp, versionBase)
+	return BumpTagMatchRegexpWithConfig(gcm, tagPrefix, tagRegexp, config)
 }
 
-// BumpTagMatchRegexp bumps Git tag version matching specified regular expression pattern
+// BumpTagMatchRegexpWithConfig bumps Git tag version matching specified regular expression pattern
 // Finds latest tag matching regexp pattern and applies version bumping logic
 // Used for both main project and submodule tag operations with custom patterns
+// The config TagName and TagPrefix are filled on a copy, the caller's config stays unchanged
 //
-// BumpTagMatchRegexp 升级匹配指定正则表达式模式的 Git 标签版本
+// BumpTagMatchRegexpWithConfig 升级匹配指定正则表达式模式的 Git 标签版本
 // 查找匹配正则表达式模式的最新标签并应用版本升级逻辑
 // 用于主项目和子模块标签操作，支持自定义模式
-func BumpTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, versionBase int) (bool, error) {
+// 配置中的 TagName 和 TagPrefix 填充在副本上，调用方的配置保持不变
+func BumpTagMatchRegexpWithConfig(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, config *BumpConfig) (*BumpResult, error) {
 	// Log regexp matching parameters for debugging
 	// 记录正则匹配参数用于调试
 	zaplog.LOG.Debug("BUMP-MATCH-REGEXP-TAG", zap.String("tag-prefix", tagPrefix), zap.String("tag-regexp", tagRegexp))
 
-	// Find latest tag matching the specified regexp pattern
-	// 查找匹配指定正则模式的最新标签
-	tagName, err := gcm.LatestGitTagMatchRegexp(tagRegexp)
+	// Find latest tag matching the specified regexp pattern, by version precedence or by date
+	// With a target ref only the tags reachable from it count, e.g. a patch on a release branch
+	// 按版本优先级或日期查找匹配指定正则模式的最新标签
+	// 配置目标引用时只统计从该引用可达的标签，例如在发布分支上打补丁
+	tagName, err := latestMergedTagMatchRegexp(gcm, tagPrefix, tagRegexp, config.TagSort, config.TargetRef)
 	if err != nil {
-		return false, erero.Wro(err)
+		return nil, erero.Wro(err)
 	}
 
-	// Validate that a matching tag was found
-	// 验证找到了匹配的标签
+	// Validate that a matching tag was found, or create the initial tag when configured
+	// 验证找到了匹配的标签，配置了初始版本时创建初始标签
 	if tagName == "" {
-		return false, erero.Errorf("not match tag name with tag-prefix=((%s)) tag-regexp=((%s))", tagPrefix, tagRegexp)
+		if config.InitialVersion != "" {
+			return InitTag(gcm, config.InitialVersion, config.withTag("", tagPrefix))
+		}
+		return nil, erero.WithMessagef(ErrNoTag, "not match tag name with tag-prefix=((%s)) tag-regexp=((%s))", tagPrefix, tagRegexp)
 	}
 
 	// Delegate to core version bumping with found tag
 	// 使用找到的标签委托给核心版本升级
-	return BumpTagNum(gcm, tagName, tagPrefix, versionBase)
+	return BumpTag(gcm, config.withTag(tagName, tagPrefix))
+}
+
+// BumpGitTag bumps the latest Git tag version with the version base and default options
+// Thin wrapper of BumpGitTagWithConfig kept for callers of the versionBase signature
+//
+// BumpGitTag 使用版本基数和默认选项升级最新的 Git 标签版本
+// BumpGitTagWithConfig 的简单封装，保留给使用 versionBase 签名的调用方
+func BumpGitTag(gcm *gitgo.Gcm, versionBase int) (*BumpResult, error) {
+	return BumpGitTagWithConfig(gcm, &BumpConfig{VersionBase: versionBase})
+}
+
+// BumpSubModuleTag bumps Git tag version for submodule with the version base and default options
+// Thin wrapper of BumpSubModuleTagWithConfig kept for callers of the versionBase signature
+//
+// BumpSubModuleTag 使用版本基数和默认选项升级子模块的 Git 标签版本
+// BumpSubModuleTagWithConfig 的简单封装，保留给使用 versionBase 签名的调用方
+func BumpSubModuleTag(gcm *gitgo.Gcm, versionBase int) (*BumpResult, error) {
+	return BumpSubModuleTagWithConfig(gcm, &BumpConfig{VersionBase: versionBase})
+}
+
+// BumpMainTag bumps Git tag version for main project with the version base and default options
+// Thin wrapper of BumpMainTagWithConfig kept for callers of the versionBase signature
+//
+// BumpMainTag 使用版本基数和默认选项升级主项目的 Git 标签版本
+// BumpMainTagWithConfig 的简单封装，保留给使用 versionBase 签名的调用方
+func BumpMainTag(gcm *gitgo.Gcm, versionBase int) (*BumpResult, error) {
+	return BumpMainTagWithConfig(gcm, &BumpConfig{VersionBase: versionBase})
+}
+
+// BumpTagMatchRegexp bumps Git tag version matching the regexp with the version base and default options
+// Thin wrapper of BumpTagMatchRegexpWithConfig kept for callers of the versionBase signature
+//
+// BumpTagMatchRegexp 使用版本基数和默认选项升级匹配正则的 Git 标签版本
+// BumpTagMatchRegexpWithConfig 的简单封装，保留给使用 versionBase 签名的调用方
+func BumpTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, versionBase int) (*BumpResult, error) {
+	return BumpTagMatchRegexpWithConfig(gcm, tagPrefix, tagRegexp, &BumpConfig{VersionBase: versionBase})
 }
 
 // BumpTagNum performs core semantic version incrementing with configurable version base
@@ -148,7 +209,7 @@ func BumpTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, vers
 // BumpTagNum 执行带可配置版本基数的核心语义版本递增
 // 处理提交哈希比较、版本解析、递增逻辑和标签创建/推送
 // 对版本基数 <= 1 支持交互式确认，更高基数支持自动模式
-func BumpTagNum(gcm *gitgo.Gcm, tagName string, tagPrefix string, versionBase int) (bool, error) {
+func BumpTagNum(gcm *gitgo.Gcm, tagName string, tagPrefix string, versionBase int) (*BumpResult, error) {
 	// Create configuration and delegate to config-based implementation
 	// 创建配置并委托给基于配置的实现
 	config := &BumpConfig{
@@ -169,14 +230,149 @@ func BumpTagNum(gcm *gitgo.Gcm, tagName string, tagPrefix string, versionBase in
 type BumpConfig struct {
 	// Basic configuration
 	// 基础配置
-	TagName     string // Current tag name to bump from // 要升级的当前标签名
-	TagPrefix   string // Tag prefix (e.g., "v", "release-") // 标签前缀（如 "v", "release-"）
-	VersionBase int    // Version base for carry-over (0/1 = interactive, >=2 = auto) // 进位的版本基数（0/1 = 交互式，>=2 = 自动）
+	TagName     string    // Current tag name to bump from // 要升级的当前标签名
+	TagPrefix   string    // Tag prefix (e.g., "v", "release-") // 标签前缀（如 "v", "release-"）
+	VersionBase int       // Version base for carry-over (0/1 = interactive, >=2 = auto) // 进位的版本基数（0/1 = 交互式，>=2 = 自动）
+	PatchBase   int       // Patch rolls over into minor at this base (0 = VersionBase) // 补丁版本达到该基数时进位到次版本（0 = VersionBase）
+	MinorBase   int       // Minor rolls over into major at this base (0 = VersionBase) // 次版本达到该基数时进位到主版本（0 = VersionBase）
+	BumpLevel   BumpLevel // Version component to bump (empty = patch) // 要升级的版本组件（空值 = 补丁）
+	PreRelease  string    // Pre-release identifier (e.g., "rc", "beta"), empty = final release // 预发布标识（如 "rc", "beta"），空值 = 正式版本
+	TagSort     TagSort   // Ordering to pick the latest tag (empty = version) // 选择最新标签的排序方式（空值 = version）
+	ModulePath  string    // Module DIR relative to repo root, scopes commits (empty = whole repo) // 相对于仓库根目录的模块目录，用于限定提交范围（空值 = 整个仓库）
+	TargetRef   string    // Commit, branch or tag to put the new tag on (empty = HEAD) // 新标签要打在的提交、分支或标签（空值 = HEAD）
+
+	// Initial tag configuration
+	// 初始标签配置
+	InitialVersion string // Version created when no tag matches the prefix (empty = fail with ErrNoTag) // 没有匹配前缀的标签时创建的版本（空值 = 返回 ErrNoTag）
+
+	// Promote configuration
+	// 晋升配置
+	PromoteAtHead bool // Tag the promoted release on HEAD instead of the pre-release commit // 在 HEAD 而非预发布提交上打晋升的正式版本标签
+
+	// Tag message and signing configuration
+	// 标签消息和签名配置
+	Annotate   bool   // Create an annotated tag, implied when TagMessage is set // 创建附注标签，设置 TagMessage 时默认启用
+	TagMessage string // Annotated tag message template with {module}/{version}/{tag} (empty = DefaultTagMessage) // 带 {module}/{version}/{tag} 的附注标签消息模板（空值 = DefaultTagMessage）
+	Sign       bool   // Create a signed tag, also enabled by tag.gpgSign of git config // 创建签名标签，git 配置的 tag.gpgSign 也会启用
+	SigningKey string // Key passed to git tag --local-user, implies Sign (empty = user.signingKey) // 传给 git tag --local-user 的密钥，设置时启用 Sign（空值 = user.signingKey）
+
+	// Release commit configuration
+	// 发布提交配置
+	UpdateChangelog bool          // Prepend the new version to CHANGELOG.md of the module, commit it and tag that commit // 在模块的 CHANGELOG.md 开头加入新版本，提交后标记该提交
+	VersionFiles    []VersionFile // Files rewritten with the new version in the release commit // 在发布提交中写入新版本的文件
+	MigrateModule   string        // New module path written into go.mod and in-module imports in the release commit // 在发布提交中写入 go.mod 和模块内导入的新模块路径
 
 	// Testing and automation options
 	// 测试和自动化选项
 	AutoConfirm bool // Auto confirm operation // 自动确认操作
+	Interactive bool // Prompt for confirmation whatever the version base, unless AutoConfirm // 无论版本基数如何都提示确认，除非设置 AutoConfirm
 	SkipGitPush bool // Skip pushing to remote // 跳过推送远程
+	DryRun      bool // Print the plan without creating, committing or pushing anything // 仅打印计划，不创建、提交或推送任何内容
+	AllowDirty  bool // Skip repo state checks (uncommitted changes, untracked files, unpushed commit) // 跳过仓库状态检查（未提交修改、未跟踪文件、未推送提交）
+
+	// Message output configuration
+	// 消息输出配置
+	MessageOutput io.Writer `json:"-"` // Writer of the dry-run plan and prompts (nil = os.Stdout) // 演练计划和提示的输出（空值 = os.Stdout）
+}
+
+// messageOutput returns the writer of the dry-run plan and prompts, os.Stdout when not configured
+//
+// messageOutput 返回演练计划和提示的输出，未配置时为 os.Stdout
+func (config *BumpConfig) messageOutput() io.Writer {
+	if config.MessageOutput != nil {
+		return config.MessageOutput
+	}
+	return os.Stdout
+}
+
+// targetRef returns the ref the new tag goes on, HEAD when not configured
+//
+// targetRef 返回新标签要打在的引用，未配置时为 HEAD
+func (config *BumpConfig) targetRef() string {
+	if config.TargetRef != "" {
+		return config.TargetRef
+	}
+	return "HEAD"
+}
+
+// withTag returns a copy of the config bound to the resolved tag name and prefix
+// Lets the high-level bump functions share one config without mutating it
+//
+// withTag 返回绑定已解析标签名和前缀的配置副本
+// 让高层升级函数共享同一配置而不修改它
+func (config *BumpConfig) withTag(tagName string, tagPrefix string) *BumpConfig {
+	newConfig := *config
+	newConfig.TagName = tagName
+	newConfig.TagPrefix = tagPrefix
+	return &newConfig
+}
+
+// patchBase returns the base patch rolls over at, < 2 means no carry-over
+//
+// patchBase 返回补丁版本的进位基数，< 2 表示不进位
+func (config *BumpConfig) patchBase() int {
+	if config.PatchBase != 0 {
+		return config.PatchBase
+	}
+	return config.VersionBase
+}
+
+// minorBase returns the base minor rolls over at, < 2 means no carry-over
+//
+// minorBase 返回次版本的进位基数，< 2 表示不进位
+func (config *BumpConfig) minorBase() int {
+	if config.MinorBase != 0 {
+		return config.MinorBase
+	}
+	return config.VersionBase
+}
+
+// checkVersionBase validates the bases and that the version components fit them
+// Returns ErrInvalidConfig on negative bases, ErrVersionBaseOverflow when a component reaches its base
+//
+// checkVersionBase 校验进位基数以及版本组件是否在基数范围内
+// 基数为负时返回 ErrInvalidConfig，组件达到其基数时返回 ErrVersionBaseOverflow
+func (config *BumpConfig) checkVersionBase(version Version) error {
+	if config.VersionBase < 0 || config.PatchBase < 0 || config.MinorBase < 0 {
+		return erero.WithMessagef(ErrInvalidConfig, "negative version base version-base=((%d)) patch-base=((%d)) minor-base=((%d))", config.VersionBase, config.PatchBase, config.MinorBase)
+	}
+	if base := config.patchBase(); base >= 2 && version.Patch >= base {
+		return erero.WithMessagef(ErrVersionBaseOverflow, "patch of ((%s)) exceeds patch-base=((%d))", version, base)
+	}
+	if base := config.minorBase(); base >= 2 && version.Minor >= base {
+		return erero.WithMessagef(ErrVersionBaseOverflow, "minor of ((%s)) exceeds minor-base=((%d))", version, base)
+	}
+	return nil
+}
+
+// BumpLevel names the version component to increment when bumping a tag
+// Lower components are reset to zero when a higher component is bumped
+//
+// BumpLevel 表示升级标签时要递增的版本组件
+// 升级较高组件时较低组件会重置为零
+type BumpLevel string
+
+const (
+	BumpMajor BumpLevel = "major" // v1.2.3 -> v2.0.0
+	BumpMinor BumpLevel = "minor" // v1.2.3 -> v1.3.0
+	BumpPatch BumpLevel = "patch" // v1.2.3 -> v1.2.4
+	BumpAuto  BumpLevel = "auto"  // Inferred from Conventional Commits since the tag // 根据自该标签以来的 Conventional Commits 推断
+)
+
+// ParseBumpLevel converts text into a BumpLevel, empty text means patch
+// Returns an error when the text is not one of major/minor/patch/auto
+//
+// ParseBumpLevel 将文本转换为 BumpLevel，空文本表示补丁
+// 当文本不是 major/minor/patch/auto 之一时返回错误
+func ParseBumpLevel(s string) (BumpLevel, error) {
+	switch bumpLevel := BumpLevel(s); bumpLevel {
+	case "":
+		return BumpPatch, nil
+	case BumpMajor, BumpMinor, BumpPatch, BumpAuto:
+		return bumpLevel, nil
+	default:
+		return "", erero.WithMessagef(ErrInvalidConfig, "unknown bump level ((%s)) expect major/minor/patch/auto", s)
+	}
 }
 
 // BumpTag performs core semantic version incrementing with flexible configuration
@@ -186,187 +382,417 @@ type BumpConfig struct {
 // BumpTag 执行带灵活配置的核心语义版本递增
 // 处理提交哈希比较、版本解析、递增逻辑和标签创建/推送
 // 使用 BumpConfig 结构提供增强的可测试性和未来扩展性
-func BumpTag(gcm *gitgo.Gcm, config *BumpConfig) (bool, error) {
+func BumpTag(gcm *gitgo.Gcm, config *BumpConfig) (*BumpResult, error) {
 	zaplog.SUG.Infoln("STARTING-BUMP-TAG", neatjsons.S(config))
 
-	// Compare commit hashes to check if tag is already at HEAD
-	// 比较提交哈希检查标签是否已在 HEAD 位置
-	tagCommitHash := rese.C1(gcm.GitCommitHash(config.TagName))
-	topCommitHash := rese.C1(gcm.GitCommitHash("main"))
+	// Compare commit hashes to check if tag is already at the target commit (HEAD by default)
+	// 比较提交哈希检查标签是否已在目标提交（默认为 HEAD）位置
+	tagCommitHash, err := resolveCommit(gcm, config.TagName)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	topCommitHash, err := resolveCommit(gcm, config.targetRef())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
 
 	zaplog.LOG.Debug("COMMIT-HASH-COMPARISON",
 		zap.String("tag-commit", tagCommitHash),
 		zap.String("top-commit", topCommitHash),
+		zap.String("target-ref", config.targetRef()),
 	)
 
+	// Refuse to tag a dirty working tree or a commit missing on remote, dry-run reports it in the plan instead
+	// 拒绝为有修改的工作区或远程不存在的提交打标签，演练模式改为在计划中报告
+	if !config.DryRun {
+		if err := checkRepoState(gcm, config, topCommitHash); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+
 	if tagCommitHash == topCommitHash {
 		// Tag is already at current commit, just push existing tag
 		// 标签已在当前提交，只需推送现有标签
 		zaplog.LOG.Info("TAG-ALREADY-AT-HEAD", zap.String("tag", config.TagName))
 
+		// Report the old tag as is, pushed or not depending on the steps below
+		// 按原样报告旧标签，是否推送取决于下面的步骤
+		result := &BumpResult{OldTag: config.TagName, CommitHash: topCommitHash}
+
+		// Show the push of the existing tag in dry-run mode
+		// 演练模式下展示现有标签的推送
+		if config.DryRun {
+			plan := &BumpPlan{TagPrefix: config.TagPrefix, OldTag: config.TagName, TargetCommit: topCommitHash}
+			plan.Preflight = preflightStatus(gcm, config, topCommitHash)
+			if !config.SkipGitPush {
+				plan.Commands = append(plan.Commands, formatGitCommand([]string{"push", "origin", config.TagName}))
+			}
+			showPlan(config.messageOutput(), plan)
+			result.Action = ActionDryRun
+			result.Plan = plan
+			return result, nil
+		}
+
 		// Check if we should proceed with pushing existing tag
 		// 检查是否应该继续推送现有标签
-		if !shouldConfirm(config, "do you want to push the old tag? "+config.TagName) {
+		confirmed, err := shouldConfirm(config, "do you want to push the old tag? "+config.TagName)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if !confirmed {
 			zaplog.LOG.Info("USER-DECLINED-PUSH-EXISTING-TAG")
-			return false, nil
+			result.Action = ActionDeclinedPush
+			return result, nil
 		}
 
 		// Skip push if configured
 		// 如果配置了则跳过推送
 		if config.SkipGitPush {
 			zaplog.LOG.Info("SKIPPING-PUSH-EXISTING-TAG", zap.String("tag", config.TagName))
-			return true, nil
+			result.Action = ActionSkippedPush
+			return result, nil
 		}
 		// Push existing tag to remote repository
 		// 推送现有标签到远程仓库
 		zaplog.LOG.Info("PUSHING-EXISTING-TAG", zap.String("tag", config.TagName))
-		result, err := gcm.PushTag(config.TagName).ShowDebugMessage().Result()
+		output, err := gcm.PushTag(config.TagName).ShowDebugMessage().Result()
 		if err != nil {
-			zaplog.SUG.Debugln(string(result))
+			zaplog.SUG.Debugln(string(output))
 			zaplog.LOG.Error("PUSH-EXISTING-TAG-FAILED", zap.Error(err))
-			return false, erero.Wro(err)
+			return nil, erero.WithMessagef(ErrPushFailed, "tag ((%s)): %v", config.TagName, err)
 		}
 		zaplog.LOG.Info("SUCCESSFULLY-PUSHED-EXISTING-TAG", zap.String("tag", config.TagName))
-		return true, nil
+		result.Pushed = true
+		result.Action = ActionPushedOldTag
+		return result, nil
 	}
 	// Log current tag name for version bumping
 	// 记录当前标签名用于版本升级
 	zaplog.LOG.Info("OLD-TAG-NAME", zap.String("tag", config.TagName))
 
-	// Construct regexp to parse semantic version format
-	// 构造正则表达式来解析语义版本格式
-	tagRegexp := `^` + regexp.QuoteMeta(config.TagPrefix) + `(\d+)\.(\d+)\.(\d+)$`
-	zaplog.LOG.Info("CHECK-TAG-NAME-FORMAT-WITH-REGEXP", zap.String("regexp", tagRegexp))
-
 	// Parse version components from tag name
 	// 从标签名解析版本组件
-	matches := regexp.MustCompile(tagRegexp).FindStringSubmatch(config.TagName)
-	if len(matches) != 4 {
-		zaplog.LOG.Error("TAG-FORMAT-MISMATCH",
-			zap.String("tag", config.TagName),
-			zap.String("regexp", tagRegexp),
-		)
-		return false, erero.New("no match")
-	}
-	// Extract major, minor, and patch version numbers
-	// 提取主版本、次版本和补丁版本号
-	vAx := done.VCE(strconv.Atoi(matches[1])).Done() // major version // 主版本号
-	vBx := done.VCE(strconv.Atoi(matches[2])).Done() // minor version // 次版本号
-	vCx := done.VCE(strconv.Atoi(matches[3])).Done() // patch version // 补丁版本
-
+	version, err := Parse(config.TagName, config.TagPrefix)
+	if err != nil {
+		zaplog.LOG.Error("TAG-FORMAT-MISMATCH", zap.String("tag", config.TagName), zap.String("tag-prefix", config.TagPrefix))
+		return nil, erero.Wro(err)
+	}
 	zaplog.LOG.Debug("PARSED-VERSION-COMPONENTS",
-		zap.Int("major", vAx),
-		zap.Int("minor", vBx),
-		zap.Int("patch", vCx))
+		zap.Int("major", version.Major),
+		zap.Int("minor", version.Minor),
+		zap.Int("patch", version.Patch),
+		zap.String("pre-release", version.PreRelease))
+
 	// Validate version components against version base for carry-over logic
 	// 验证版本组件与版本基数的进位逻辑
-	if config.VersionBase >= 2 {
-		mustnum.Less(vBx, config.VersionBase)
-		mustnum.Less(vCx, config.VersionBase)
-	}
-
-	// Increment patch version by default
-	// 默认递增补丁版本
-	vCx++
-	zaplog.LOG.Debug("INCREMENTING-VERSION", zap.Int("new-patch", vCx))
-
-	// Apply version carry-over logic for automatic mode
-	// 为自动模式应用版本进位逻辑
-	if config.VersionBase >= 2 { // When 0 or 1, no automatic version carry-over; >= 2 enables it // 当是0或者1时，标签不自动进位；>=2时启用自动进位
-		// Check if patch version needs to carry over to minor
-		// 检查补丁版本是否需要进位到次版本
-		if vCx >= config.VersionBase {
-			vCx = 0
-			vBx++
+	if err := config.checkVersionBase(version); err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Resolve auto bump level from Conventional Commits since the old tag
+	// 根据自旧标签以来的 Conventional Commits 解析自动升级级别
+	if config.BumpLevel == BumpAuto {
+		commits, err := ListCommits(gcm, config.TagName, topCommitHash, config.ModulePath)
+		if err != nil {
+			return nil, erero.Wro(err)
 		}
-		// Check if minor version needs to carry over to major
-		// 检查次版本是否需要进位到主版本
-		if vBx >= config.VersionBase {
-			vBx = 0
-			vAx++
+		if len(commits) == 0 {
+			return nil, erero.WithMessagef(ErrNoCommits, "no commits touching module path ((%s)) since tag ((%s))", config.ModulePath, config.TagName)
 		}
+		autoConfig := *config
+		autoConfig.BumpLevel = InferBumpLevel(commits)
+		config = &autoConfig
+		zaplog.LOG.Info("INFERRED-BUMP-LEVEL", zap.Int("commits", len(commits)), zap.String("bump-level", string(config.BumpLevel)))
+	}
+
+	// Compute the next version with bump level, pre-release and carry-over
+	// 使用升级级别、预发布和进位计算下一个版本
+	newVersion, err := NextVersion(version, config)
+	if err != nil {
+		return nil, erero.Wro(err)
 	}
+
 	// Construct new tag name with incremented version
 	// 构造带递增版本的新标签名
-	newTagName := fmt.Sprintf("%s%d.%d.%d", config.TagPrefix, vAx, vBx, vCx)
+	newTagName := newVersion.String()
 	zaplog.LOG.Info("NEW-TAG-NAME", zap.String("tag", newTagName))
 
+	// Create and push the new tag on current HEAD, or on the target commit when configured
+	// 在当前 HEAD 上创建并推送新标签，配置了目标引用时打在目标提交上
+	var targetRef string
+	if config.TargetRef != "" {
+		targetRef = topCommitHash
+	}
+	return createAndPushTag(gcm, config, newTagName, targetRef)
+}
+
+// NextVersion computes the version BumpTag would create from the given version, without touching git
+// Applies bump level, pre-release identifier and version base carry-over from the config
+//
+// NextVersion 计算 BumpTag 会基于给定版本创建的版本，不涉及 git 操作
+// 应用配置中的升级级别、预发布标识和版本基数进位
+func NextVersion(version Version, config *BumpConfig) (Version, error) {
+	// Increment the version component selected by bump level, with pre-release when configured
+	// 递增升级级别选择的版本组件，配置了预发布时附带预发布标识
+	var newVersion Version
+	var err error
+	if config.PreRelease != "" {
+		newVersion, err = version.BumpPreRelease(config.BumpLevel, config.PreRelease)
+	} else {
+		newVersion, err = version.Bump(config.BumpLevel)
+	}
+	if err != nil {
+		return Version{}, erero.Wro(err)
+	}
+	zaplog.LOG.Debug("INCREMENTING-VERSION",
+		zap.String("bump-level", string(config.BumpLevel)),
+		zap.String("new-version", newVersion.String()))
+
+	// Apply version carry-over logic for automatic mode, only when the core version moved
+	// Each component carries over at its own base, falling back to VersionBase
+	// 为自动模式应用版本进位逻辑，仅在核心版本变化时
+	// 每个组件按各自的基数进位，未设置时回退到 VersionBase
+	if newVersion.Core() != version.Core() {
+		// Check if patch version needs to carry over to minor, when 0 or 1 no automatic carry-over; >= 2 enables it
+		// 检查补丁版本是否需要进位到次版本，当是0或者1时不自动进位；>=2时启用自动进位
+		if base := config.patchBase(); base >= 2 && newVersion.Patch >= base {
+			newVersion.Patch = 0
+			newVersion.Minor++
+		}
+		// Check if minor version needs to carry over to major
+		// 检查次版本是否需要进位到主版本
+		if base := config.minorBase(); base >= 2 && newVersion.Minor >= base {
+			newVersion.Minor = 0
+			newVersion.Major++
+		}
+	}
+
+	// Ensure the new version ranks above the old one, e.g. switching rc back to beta is refused
+	// 确保新版本高于旧版本，例如从 rc 切回 beta 会被拒绝
+	if newVersion.Compare(version) <= 0 {
+		return Version{}, erero.WithMessagef(ErrVersionNotGreater, "new version ((%s)) old version ((%s))", newVersion, version)
+	}
+	return newVersion, nil
+}
+
+// createAndPushTag creates the new tag with confirmation and pushes it to remote
+// Tags current HEAD when targetRef is empty, else tags the commit the targetRef points to
+// Shared by bump and promote operations to keep one confirm/create/push flow
+// Makes the release commit first when configured, rolled back with the tag when a later step fails
+//
+// createAndPushTag 在确认后创建新标签并推送到远程
+// targetRef 为空时标记当前 HEAD，否则标记 targetRef 指向的提交
+// 由升级和晋升操作共享，保持统一的确认/创建/推送流程
+// 配置时先创建发布提交，后续步骤失败时与标签一起回滚
+func createAndPushTag(gcm *gitgo.Gcm, config *BumpConfig, newTagName string, targetRef string) (*BumpResult, error) {
+	result := &BumpResult{OldTag: config.TagName, NewTag: newTagName}
+
+	// Refuse a v2+ tag the go command cannot use with the module path in go.mod
+	// 拒绝 go 命令无法配合 go.mod 中模块路径使用的 v2 及以上标签
+	if err := checkModuleMajor(gcm, config, newTagName); err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Show the plan and stop in dry-run mode
+	// 演练模式下展示计划后停止
+	if config.DryRun {
+		plan, err := planCreateAndPushTag(gcm, config, newTagName, targetRef)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		showPlan(config.messageOutput(), plan)
+		result.CommitHash = plan.TargetCommit
+		result.Action = ActionDryRun
+		result.Plan = plan
+		return result, nil
+	}
+
 	// Check if we should proceed with creating new tag
 	// 检查是否应该继续创建新标签
-	if !shouldConfirm(config, "do you want to set this new tag? "+newTagName) {
+	confirmed, err := shouldConfirm(config, "do you want to set this new tag? "+newTagName)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if !confirmed {
 		zaplog.LOG.Info("USER-DECLINED-CREATE-TAG", zap.String("tag", newTagName))
-		return false, nil
+		result.Action = ActionDeclinedCreate
+		return result, nil
+	}
+
+	// Commit the release files first, the new tag goes on the release commit
+	// 先提交发布文件，新标签打在发布提交上
+	var release *releaseCommit
+	if config.needsReleaseCommit() {
+		release, err = createReleaseCommit(gcm, config, newTagName, targetRef)
+		if err != nil {
+			zaplog.LOG.Error("RELEASE-COMMIT-FAILED", zap.String("tag", newTagName), zap.Error(err))
+			return nil, erero.WithMessagef(ErrReleaseCommitFailed, "tag ((%s)): %v", newTagName, err)
+		}
+		targetRef = ""
 	}
 
 	// Create new tag in local repository
 	// 在本地仓库创建新标签
-	zaplog.LOG.Info("CREATING-NEW-TAG", zap.String("tag", newTagName))
-	result, err := gcm.Tag(newTagName).ShowDebugMessage().Result()
+	zaplog.LOG.Info("CREATING-NEW-TAG", zap.String("tag", newTagName), zap.String("target-ref", targetRef))
+	output, err := createTag(gcm, config, newTagName, targetRef)
 	if err != nil {
-		zaplog.SUG.Debugln(string(result))
+		zaplog.SUG.Debugln(string(output))
 		zaplog.LOG.Error("TAG-CREATION-FAILED", zap.String("tag", newTagName), zap.Error(err))
-		return false, erero.Wro(err)
+		if release != nil {
+			release.rollback(gcm, "")
+		}
+		return nil, erero.WithMessagef(ErrTagCreateFailed, "tag ((%s)): %v", newTagName, err)
 	}
 	zaplog.LOG.Info("SUCCESSFULLY-CREATED-TAG", zap.String("tag", newTagName))
+	result.Created = true
+	if result.CommitHash, err = resolveCommit(gcm, newTagName); err != nil {
+		return nil, erero.Wro(err)
+	}
+
 	// Check if we should proceed with pushing new tag
 	// 检查是否应该继续推送新标签
-	if !shouldConfirm(config, "do you want to push the new tag? "+newTagName) {
+	confirmed, err = shouldConfirm(config, "do you want to push the new tag? "+newTagName)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if !confirmed {
 		zaplog.LOG.Info("USER-DECLINED-PUSH-NEW-TAG", zap.String("tag", newTagName))
-		return true, nil // Tag created but not pushed
+		result.Action = ActionDeclinedPush // Tag created but not pushed
+		return result, nil
 	}
 
 	// Skip push if configured
 	// 如果配置了则跳过推送
 	if config.SkipGitPush {
 		zaplog.LOG.Info("SKIPPING-TAG-PUSH", zap.String("tag", newTagName))
-		return true, nil
+		result.Action = ActionSkippedPush
+		return result, nil
 	}
-	// Push new tag to remote repository
-	// 推送新标签到远程仓库
-	zaplog.LOG.Info("PUSHING-NEW-TAG", zap.String("tag", newTagName))
-	result, err = gcm.PushTag(newTagName).ShowDebugMessage().Result()
-	if err != nil {
-		zaplog.SUG.Debugln(string(result))
-		zaplog.LOG.Error("PUSH-NEW-TAG-FAILED", zap.String("tag", newTagName), zap.Error(err))
-		return false, erero.Wro(err)
+
+	// Push the release commit together with the new tag, roll both back when the push fails
+	// 将发布提交与新标签一起推送，推送失败时回滚两者
+	if release != nil {
+		if err := release.push(gcm, newTagName); err != nil {
+			zaplog.LOG.Error("PUSH-RELEASE-FAILED", zap.String("tag", newTagName), zap.Error(err))
+			release.rollback(gcm, newTagName)
+			return nil, erero.WithMessagef(ErrPushFailed, "tag ((%s)) with release commit, tag and commit rolled back: %v", newTagName, err)
+		}
+	} else {
+		// Push new tag to remote repository
+		// 推送新标签到远程仓库
+		zaplog.LOG.Info("PUSHING-NEW-TAG", zap.String("tag", newTagName))
+		output, err = gcm.PushTag(newTagName).ShowDebugMessage().Result()
+		if err != nil {
+			zaplog.SUG.Debugln(string(output))
+			zaplog.LOG.Error("PUSH-NEW-TAG-FAILED", zap.String("tag", newTagName), zap.Error(err))
+			return nil, erero.WithMessagef(ErrPushFailed, "tag ((%s)): %v", newTagName, err)
+		}
 	}
 	zaplog.LOG.Info("SUCCESSFULLY-PUSHED-NEW-TAG", zap.String("tag", newTagName))
-	return true, nil
+	result.Pushed = true
+	result.Action = ActionPushed
+	return result, nil
+}
+
+// createTag creates a lightweight, annotated or signed tag on HEAD or on the given target ref
+// Uses gcm for a lightweight tag on HEAD, runs git in the repo top path otherwise
+//
+// createTag 在 HEAD 或给定目标引用上创建轻量标签、附注标签或签名标签
+// 在 HEAD 上创建轻量标签时使用 gcm，其它情况在仓库根路径执行 git
+func createTag(gcm *gitgo.Gcm, config *BumpConfig, tagName string, targetRef string) ([]byte, error) {
+	args, err := tagCommandArgs(gcm, config, tagName, targetRef)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if len(args) == 2 {
+		return gcm.Tag(tagName).ShowDebugMessage().Result()
+	}
+	output, err := runGit(gcm, args...)
+	return []byte(output), err
+}
+
+// tagCommandArgs returns the git args creating the tag, ["tag", tagName] for a lightweight tag on HEAD
+//
+// tagCommandArgs 返回创建标签的 git 参数，在 HEAD 上创建轻量标签时为 ["tag", tagName]
+func tagCommandArgs(gcm *gitgo.Gcm, config *BumpConfig, tagName string, targetRef string) ([]string, error) {
+	sign := shouldSignTag(gcm, config)
+	message, err := tagMessage(gcm, config, tagName, sign)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	args := []string{"tag"}
+	if sign {
+		args = append(args, "--sign")
+		if config.SigningKey != "" {
+			args = append(args, "--local-user", config.SigningKey)
+		}
+	} else if message != "" {
+		args = append(args, "--annotate")
+	}
+	if message != "" {
+		args = append(args, "--message", message)
+	}
+	args = append(args, tagName)
+	if targetRef != "" {
+		args = append(args, targetRef)
+	}
+	return args, nil
 }
 
 // shouldConfirm determines whether to proceed with an operation based on config
-// Uses config flags to control confirmation flow
-// Returns true to proceed, false to skip the operation
+// AutoConfirm and Interactive choose the mode, else version base <= 1 prompts and >= 2 proceeds
+// A prompt without a terminal on stdin fails with ErrConfirmFailed instead of blocking or guessing yes
+// Returns true to proceed, false to skip the operation, an error when the prompt fails
 //
 // shouldConfirm 根据配置确定是否继续操作
-// 使用配置标志控制确认流程
-// 返回 true 继续，false 跳过操作
-func shouldConfirm(config *BumpConfig, message string) bool {
+// AutoConfirm 和 Interactive 选择模式，否则版本基数 <= 1 时提示，>= 2 时继续
+// stdin 不是终端时需要提示则返回 ErrConfirmFailed，而不是阻塞或默认同意
+// 返回 true 继续，false 跳过操作，提示失败时返回错误
+func shouldConfirm(config *BumpConfig, message string) (bool, error) {
 	// Auto-confirm if explicitly configured
 	// 如果明确配置则自动确认
 	if config.AutoConfirm {
-		return true
+		return true, nil
 	}
 
-	// Interactive confirmation for version base <= 1
-	// 对于版本基数 <= 1 使用交互式确认
-	if config.VersionBase <= 1 {
-		return chooseConfirm(message)
+	// Auto-proceed for higher version bases when interactive mode is not requested
+	// 未请求交互模式时，对于更高版本基数自动继续
+	if !config.Interactive && config.VersionBase >= 2 {
+		return true, nil
+	}
+
+	// No terminal to answer the prompt, fail instead of blocking, AutoConfirm is the way to go on
+	// 没有可回答提示的终端，返回失败而非阻塞，需要通过 AutoConfirm 继续
+	if !stdinIsTerminal() {
+		zaplog.LOG.Warn("STDIN-NOT-TERMINAL-CANNOT-CONFIRM", zap.String("message", message))
+		return false, erero.WithMessagef(ErrConfirmFailed, "stdin is not a terminal, pass --yes to proceed without prompts: %s", message)
 	}
 
-	// Auto-proceed for higher version bases
-	// 对于更高版本基数自动继续
-	return true
+	// Interactive confirmation
+	// 交互式确认
+	return chooseConfirm(config.messageOutput(), message)
+}
+
+// stdinIsTerminal tells whether stdin is a terminal able to answer prompts, replaced in tests
+//
+// stdinIsTerminal 判断 stdin 是否为可回答提示的终端，测试中可替换
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
 }
 
 // chooseConfirm displays interactive confirmation prompt with yes/no options
 // Uses survey package to present user-friendly confirmation dialog
 // Returns true for yes, false for no, with default value of true
+// Returns ErrConfirmFailed when no answer can be read, e.g. stdin is not a terminal
+// The prompt and the answer go to the writer, the prompt stays on stdout when the writer is not a file
 //
 // chooseConfirm 显示交互式确认提示，带有是/否选项
 // 使用 survey 包呈现用户友好的确认对话框
 // 返回 true 表示是，false 表示否，默认值为 true
-func chooseConfirm(msg string) bool {
+// 无法读取回答时返回 ErrConfirmFailed，例如 stdin 不是终端
+// 提示和回答输出到 writer，writer 不是文件时提示仍输出到 stdout
+func chooseConfirm(writer io.Writer, msg string) (bool, error) {
 	// Variable to store user response
 	// 用于存储用户的回答
 	var input bool
@@ -380,14 +806,20 @@ func chooseConfirm(msg string) bool {
 
 	// Run prompt and capture user input
 	// 运行提示并捕获用户输入的内容
-	done.Done(survey.AskOne(prompt, &input))
+	var options []survey.AskOpt
+	if file, ok := writer.(*os.File); ok {
+		options = append(options, survey.WithStdio(os.Stdin, file, os.Stderr))
+	}
+	if err := survey.AskOne(prompt, &input, options...); err != nil {
+		return false, erero.WithMessagef(ErrConfirmFailed, "%s: %v", msg, err)
+	}
 
 	// Output user response
 	// 输出用户的回答
 	if input {
-		fmt.Println("You chose Yes")
-		return true
+		_, _ = fmt.Fprintln(writer, "You chose Yes")
+		return true, nil
 	}
-	fmt.Println("You chose Not")
-	return false
+	_, _ = fmt.Fprintln(writer, "You chose Not")
+	return false, nil
 }
@@ -77,9 +77,12 @@ func TestBumpTag_CurrentTagAtHead(t *testing.T) {
 		SkipGitPush: true,
 	}
 
-	success, err := BumpTag(gcm, config)
+	result, err := BumpTag(gcm, config)
 	require.NoError(t, err)
-	require.True(t, success)
+	require.False(t, result.Created)
+	require.Equal(t, ActionSkippedPush, result.Action)
+	require.Equal(t, "v0.0.1", result.OldTag)
+	require.Equal(t, "", result.NewTag)
 
 	tags := rese.C1(gcm.SortedGitTags())
 	t.Log(tags)
@@ -108,9 +111,9 @@ func TestBumpTag_VersionIncrement(t *testing.T) {
 			SkipGitPush: true,
 		}
 
-		success, err := BumpTag(gcm, config)
+		result, err := BumpTag(gcm, config)
 		require.NoError(t, err)
-		require.True(t, success)
+		require.True(t, result.Created)
 
 		tags := rese.C1(gcm.SortedGitTags())
 		t.Log(tags)
@@ -132,9 +135,9 @@ func TestBumpTag_VersionIncrement(t *testing.T) {
 			SkipGitPush: true,
 		}
 
-		success, err := BumpTag(gcm, config)
+		result, err := BumpTag(gcm, config)
 		require.NoError(t, err)
-		require.True(t, success)
+		require.True(t, result.Created)
 
 		tags := rese.C1(gcm.SortedGitTags())
 		t.Log(tags)
@@ -164,9 +167,9 @@ func TestBumpTag_DifferentVersionBases(t *testing.T) {
 			SkipGitPush: true,
 		}
 
-		success, err := BumpTag(gcm, config)
+		result, err := BumpTag(gcm, config)
 		require.NoError(t, err)
-		require.True(t, success)
+		require.True(t, result.Created)
 
 		tags := rese.C1(gcm.SortedGitTags())
 		t.Log(tags)
@@ -188,12 +191,347 @@ func TestBumpTag_DifferentVersionBases(t *testing.T) {
 			SkipGitPush: true,
 		}
 
-		success, err := BumpTag(gcm, config)
+		result, err := BumpTag(gcm, config)
 		require.NoError(t, err)
-		require.True(t, success)
+		require.True(t, result.Created)
 
 		tags := rese.C1(gcm.SortedGitTags())
 		t.Log(tags)
 		require.Contains(t, tags, "refs/tags/v0.0.3")
 	})
 }
+
+// commitTestFile writes a file and commits it to move HEAD forward in the test repository
+// Environment setup must succeed, so we use rese/must for all operations
+func commitTestFile(tempDIR string, name string) {
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+
+	must.Done(os.WriteFile(filepath.Join(tempDIR, name), []byte(name), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "Add "+name))
+}
+
+func TestBumpTag_BumpLevel(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "-d", "v0.0.1"))
+	rese.V1(execConfig.Exec("git", "tag", "v1.2.3"))
+
+	testCases := []struct {
+		tagName   string
+		bumpLevel BumpLevel
+		expected  string
+	}{
+		{tagName: "v1.2.3", bumpLevel: BumpPatch, expected: "v1.2.4"},
+		{tagName: "v1.2.4", bumpLevel: BumpMinor, expected: "v1.3.0"},
+		{tagName: "v1.3.0", bumpLevel: BumpMajor, expected: "v2.0.0"},
+		{tagName: "v2.0.0", bumpLevel: "", expected: "v2.0.1"},
+	}
+	for _, tc := range testCases {
+		require.True(t, t.Run(string(tc.bumpLevel)+"-"+tc.expected, func(t *testing.T) {
+			commitTestFile(tempDIR, tc.expected+".txt")
+
+			config := &BumpConfig{
+				TagName:     tc.tagName,
+				TagPrefix:   "v",
+				VersionBase: 100,
+				BumpLevel:   tc.bumpLevel,
+				AutoConfirm: true,
+				SkipGitPush: true,
+			}
+
+			result, err := BumpTag(gcm, config)
+			require.NoError(t, err)
+			require.True(t, result.Created)
+
+			tags := rese.C1(gcm.SortedGitTags())
+			t.Log(tags)
+			require.Contains(t, tags, "refs/tags/"+tc.expected)
+		}))
+	}
+}
+
+func TestBumpTag_BumpLevelCarryOver(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "v1.9.5"))
+	commitTestFile(tempDIR, "test.txt")
+
+	config := &BumpConfig{
+		TagName:     "v1.9.5",
+		TagPrefix:   "v",
+		VersionBase: 10,
+		BumpLevel:   BumpMinor,
+		AutoConfirm: true,
+		SkipGitPush: true,
+	}
+
+	result, err := BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	tags := rese.C1(gcm.SortedGitTags())
+	t.Log(tags)
+	require.Contains(t, tags, "refs/tags/v2.0.0")
+}
+
+func TestParseBumpLevel(t *testing.T) {
+	bumpLevel, err := ParseBumpLevel("")
+	require.NoError(t, err)
+	require.Equal(t, BumpPatch, bumpLevel)
+
+	bumpLevel, err = ParseBumpLevel("minor")
+	require.NoError(t, err)
+	require.Equal(t, BumpMinor, bumpLevel)
+
+	_, err = ParseBumpLevel("huge")
+	require.Error(t, err)
+}
+
+func TestBumpTag_PreRelease(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "v1.2.0"))
+
+	testCases := []struct {
+		tagName    string
+		bumpLevel  BumpLevel
+		preRelease string
+		expected   string
+	}{
+		{tagName: "v1.2.0", bumpLevel: BumpMinor, preRelease: "rc", expected: "v1.3.0-rc.1"},
+		{tagName: "v1.3.0-rc.1", bumpLevel: "", preRelease: "rc", expected: "v1.3.0-rc.2"},
+		{tagName: "v1.3.0-rc.2", bumpLevel: BumpMinor, preRelease: "rc", expected: "v1.3.0-rc.3"},
+		{tagName: "v1.3.0-rc.3", bumpLevel: BumpMajor, preRelease: "beta", expected: "v2.0.0-beta.1"},
+		{tagName: "v2.0.0-beta.1", bumpLevel: "", preRelease: "rc", expected: "v2.0.0-rc.1"},
+		{tagName: "v2.0.0-rc.1", bumpLevel: "", preRelease: "", expected: "v2.0.0"},
+	}
+	for _, tc := range testCases {
+		require.True(t, t.Run(tc.expected, func(t *testing.T) {
+			commitTestFile(tempDIR, tc.expected+".txt")
+
+			config := &BumpConfig{
+				TagName:     tc.tagName,
+				TagPrefix:   "v",
+				VersionBase: 100,
+				BumpLevel:   tc.bumpLevel,
+				PreRelease:  tc.preRelease,
+				AutoConfirm: true,
+				SkipGitPush: true,
+			}
+
+			result, err := BumpTag(gcm, config)
+			require.NoError(t, err)
+			require.True(t, result.Created)
+
+			tags := rese.C1(gcm.SortedGitTags())
+			t.Log(tags)
+			require.Contains(t, tags, "refs/tags/"+tc.expected)
+		}))
+	}
+}
+
+func TestPreferReleaseTag(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "tag", "v0.1.0-rc.1"))
+	rese.V1(execConfig.Exec("git", "tag", "v0.1.0"))
+	rese.V1(execConfig.Exec("git", "tag", "v0.2.0-rc.1"))
+
+	require.Equal(t, "v0.1.0", preferReleaseTag(gcm, "v0.1.0-rc.1", "v"))
+	require.Equal(t, "v0.2.0-rc.1", preferReleaseTag(gcm, "v0.2.0-rc.1", "v"))
+	require.Equal(t, "v0.0.1", preferReleaseTag(gcm, "v0.0.1", "v"))
+}
+
+func TestBumpTag_MasterBranch(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	rese.V1(execConfig.Exec("git", "branch", "-M", "master"))
+	commitTestFile(tempDIR, "test.txt")
+
+	config := &BumpConfig{
+		TagName:     "v0.0.1",
+		TagPrefix:   "v",
+		VersionBase: 100,
+		AutoConfirm: true,
+		SkipGitPush: true,
+	}
+
+	result, err := BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("v0.0.2")))
+}
+
+func TestBumpTag_TargetRef(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitTestFile(tempDIR, "release.txt")
+	rese.V1(execConfig.Exec("git", "branch", "release"))
+	commitTestFile(tempDIR, "test.txt")
+
+	config := &BumpConfig{
+		TagName:     "v0.0.1",
+		TagPrefix:   "v",
+		VersionBase: 100,
+		TargetRef:   "release",
+		AutoConfirm: true,
+		SkipGitPush: true,
+	}
+
+	result, err := BumpTag(gcm, config)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	require.Equal(t, rese.C1(gcm.GitCommitHash("release")), rese.C1(gcm.GitCommitHash("v0.0.2")))
+	require.NotEqual(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("v0.0.2")))
+
+	// Target ref that does not exist is reported as an error
+	config.TargetRef = "missing-branch"
+	_, err = BumpTag(gcm, config)
+	require.Error(t, err)
+}
+
+func TestBumpMainTag_TargetRefLatest(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	commitTestFile(tempDIR, "release.txt")
+	rese.V1(execConfig.Exec("git", "tag", "v1.4.0"))
+	commitTestFile(tempDIR, "fix.txt")
+	rese.V1(execConfig.Exec("git", "branch", "release"))
+	commitTestFile(tempDIR, "test.txt")
+	rese.V1(execConfig.Exec("git", "tag", "v2.0.0"))
+
+	// The release branch bumps from its own latest tag, v2.0.0 is not reachable from it
+	result, err := BumpMainTagWithConfig(gcm, &BumpConfig{TargetRef: "release", DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, "v1.4.0", result.OldTag)
+	require.Equal(t, "v1.4.1", result.NewTag)
+
+	result, err = BumpGitTagWithConfig(gcm, &BumpConfig{TargetRef: "release", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v1.4.0", result.OldTag)
+	require.Equal(t, "v1.4.1", result.NewTag)
+	require.Equal(t, rese.C1(gcm.GitCommitHash("release")), rese.C1(gcm.GitCommitHash("v1.4.1")))
+
+	// Without the target ref the latest tag of the repo counts
+	result, err = BumpMainTagWithConfig(gcm, &BumpConfig{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", result.OldTag)
+}
+
+func TestBumpTag_Result(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitTestFile(tempDIR, "test.txt")
+
+	gcm := gitgo.New(tempDIR)
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	t.Log(result.String())
+	require.Equal(t, &BumpResult{
+		OldTag:     "v0.0.1",
+		NewTag:     "v0.0.2",
+		CommitHash: rese.C1(gcm.GitCommitHash("HEAD")),
+		Created:    true,
+		Pushed:     false,
+		Action:     ActionSkippedPush,
+	}, result)
+	require.False(t, result.Declined())
+}
+
+func TestBumpTag_VersionBaseOverflow(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	commitTestFile(tempDIR, "test.txt")
+	rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "tag", "v0.12.0"))
+
+	gcm := gitgo.New(tempDIR)
+	commitTestFile(tempDIR, "next.txt")
+
+	// Minor 12 does not fit version base 10, an error is returned instead of a panic
+	_, err := BumpTag(gcm, &BumpConfig{TagName: "v0.12.0", TagPrefix: "v", VersionBase: 10, AutoConfirm: true, SkipGitPush: true})
+	require.ErrorIs(t, err, ErrVersionBaseOverflow)
+
+	// Version base 100 accepts it
+	result, err := BumpTag(gcm, &BumpConfig{TagName: "v0.12.0", TagPrefix: "v", VersionBase: 100, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.Equal(t, "v0.12.1", result.NewTag)
+}
+
+func TestBumpTag_Errors(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+
+	require.True(t, t.Run("No Tag", func(t *testing.T) {
+		_, err := BumpTagMatchRegexpWithConfig(gcm, "sub/v", "sub/v[0-9]*.[0-9]*.[0-9]*", &BumpConfig{AutoConfirm: true, SkipGitPush: true})
+		require.ErrorIs(t, err, ErrNoTag)
+	}))
+
+	require.True(t, t.Run("Tag Format", func(t *testing.T) {
+		commitTestFile(tempDIR, "format.txt")
+		_, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "release-", AutoConfirm: true, SkipGitPush: true})
+		require.ErrorIs(t, err, ErrTagFormat)
+	}))
+
+	require.True(t, t.Run("Version Not Greater", func(t *testing.T) {
+		rese.V1(osexec.NewExecConfig().WithPath(tempDIR).Exec("git", "tag", "v0.1.0-rc.1"))
+		commitTestFile(tempDIR, "beta.txt")
+		_, err := BumpTag(gcm, &BumpConfig{TagName: "v0.1.0-rc.1", TagPrefix: "v", BumpLevel: BumpMinor, PreRelease: "beta", AutoConfirm: true, SkipGitPush: true})
+		require.ErrorIs(t, err, ErrVersionNotGreater)
+	}))
+
+	require.True(t, t.Run("Push Failed", func(t *testing.T) {
+		// No origin remote, the local tag is created but the push fails
+		_, err := BumpTag(gcm, &BumpConfig{TagName: "v0.0.1", TagPrefix: "v", AllowDirty: true, AutoConfirm: true})
+		require.ErrorIs(t, err, ErrPushFailed)
+	}))
+}
+
+func TestShouldConfirm(t *testing.T) {
+	// Tests never answer prompts, stdin is treated as not a terminal
+	original := stdinIsTerminal
+	stdinIsTerminal = func() bool { return false }
+	defer func() { stdinIsTerminal = original }()
+
+	require.True(t, rese.V1(shouldConfirm(&BumpConfig{AutoConfirm: true}, "confirm?")))
+	require.True(t, rese.V1(shouldConfirm(&BumpConfig{AutoConfirm: true, Interactive: true}, "confirm?")))
+	require.True(t, rese.V1(shouldConfirm(&BumpConfig{VersionBase: 10}, "confirm?")))
+
+	// Without a terminal the prompt fails instead of blocking or guessing yes
+	_, err := shouldConfirm(&BumpConfig{VersionBase: 0}, "confirm?")
+	require.ErrorIs(t, err, ErrConfirmFailed)
+	require.Contains(t, err.Error(), "--yes")
+
+	// Interactive mode needs a terminal, also with carry-over
+	_, err = shouldConfirm(&BumpConfig{Interactive: true}, "confirm?")
+	require.ErrorIs(t, err, ErrConfirmFailed)
+	_, err = shouldConfirm(&BumpConfig{VersionBase: 10, Interactive: true}, "confirm?")
+	require.ErrorIs(t, err, ErrConfirmFailed)
+}
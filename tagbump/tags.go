@@ -0,0 +1,227 @@
+package tagbump
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// TagSort names the ordering used to list tags and to pick the latest tag
+// Version ordering follows SemVer precedence, date ordering follows tag creation date
+//
+// TagSort 表示列出标签和选择最新标签时使用的排序方式
+// 版本排序遵循 SemVer 优先级，日期排序遵循标签创建日期
+type TagSort string
+
+const (
+	TagSortVersion TagSort = "version" // Order by SemVer precedence (default) // 按 SemVer 优先级排序（默认）
+	TagSortDate    TagSort = "date"    // Order by tag creation date // 按标签创建日期排序
+)
+
+// ParseTagSort converts text into a TagSort, empty text means version
+// Returns an error when the text is not one of version/date
+//
+// ParseTagSort 将文本转换为 TagSort，空文本表示 version
+// 当文本不是 version/date 之一时返回错误
+func ParseTagSort(s string) (TagSort, error) {
+	switch tagSort := TagSort(s); tagSort {
+	case "":
+		return TagSortVersion, nil
+	case TagSortVersion, TagSortDate:
+		return tagSort, nil
+	default:
+		return "", erero.WithMessagef(ErrInvalidConfig, "unknown tag sort ((%s)) expect version/date", s)
+	}
+}
+
+// GitTag describes one tag of the repository
+//
+// GitTag 描述仓库中的一个标签
+type GitTag struct {
+	Name   string    `json:"name"`   // Tag name without refs/tags/ // 不带 refs/tags/ 的标签名
+	Date   string    `json:"-"`      // Tag creation date in git default format // git 默认格式的标签创建日期
+	Time   time.Time `json:"date"`   // Tag creation time // 标签创建时间
+	Commit string    `json:"commit"` // Commit the tag points to, annotated tags are peeled // 标签指向的提交，附注标签会被剥离
+}
+
+// ListGitTags lists all tags of the repository in the given order
+// Version ordering groups tags by prefix (v, sub/v) and sorts each group by SemVer precedence
+// Tags not in version format are kept at the end in date order
+//
+// ListGitTags 按给定顺序列出仓库的所有标签
+// 版本排序按前缀（v, sub/v）分组，每组按 SemVer 优先级排序
+// 非版本格式的标签按日期顺序保留在末尾
+func ListGitTags(gcm *gitgo.Gcm, tagSort TagSort) ([]*GitTag, error) {
+	output, err := runGit(gcm, "for-each-ref", "--sort=creatordate", "--format=%(refname:strip=2)%09%(*objectname)%09%(objectname)%09%(creatordate:iso-strict)%09%(creatordate)", "refs/tags")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Parse one tag per line with tab separated name, peeled object, object and dates
+	// The peeled object is empty for lightweight tags, so it stays inside the line
+	// 每行解析一个标签，名称、剥离后的对象、对象和日期以制表符分隔
+	// 轻量标签的剥离对象为空，因此放在行的中间
+	var tags []*GitTag
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			return nil, erero.Errorf("wrong tag line ((%s))", line)
+		}
+		tag := &GitTag{Name: fields[0], Commit: fields[1], Date: fields[4]}
+		if tag.Commit == "" {
+			tag.Commit = fields[2]
+		}
+		if tag.Time, err = time.Parse(time.RFC3339, fields[3]); err != nil {
+			return nil, erero.Wro(err)
+		}
+		tags = append(tags, tag)
+	}
+
+	switch tagSort {
+	case TagSortDate:
+		return tags, nil
+	case "", TagSortVersion:
+		slices.SortStableFunc(tags, func(a, b *GitTag) int {
+			return compareTagName(a.Name, b.Name)
+		})
+		return tags, nil
+	default:
+		return nil, erero.WithMessagef(ErrInvalidConfig, "unknown tag sort ((%s))", tagSort)
+	}
+}
+
+// ParseTagName parses a tag name into a Version, detecting the prefix before the version numbers
+// "sub/v1.2.3" gets prefix "sub/v", "release-1.0.0" gets prefix "release-"
+//
+// ParseTagName 将标签名解析为 Version，自动识别版本号之前的前缀
+// "sub/v1.2.3" 得到前缀 "sub/v"，"release-1.0.0" 得到前缀 "release-"
+func ParseTagName(tagName string) (Version, error) {
+	// A prefix ending with a digit means a leading zero, v01.2.3 would get prefix "v0"
+	// 以数字结尾的前缀意味着存在前导零，v01.2.3 会得到前缀 "v0"
+	matches := tagNameRegexp.FindStringSubmatch(tagName)
+	if len(matches) != 2 || strings.TrimRight(matches[1], "0123456789") != matches[1] {
+		return Version{}, erero.WithMessagef(ErrTagFormat, "tag ((%s))", tagName)
+	}
+	return Parse(tagName, matches[1])
+}
+
+// compareTagName orders tag names by prefix then by SemVer precedence
+// Tag names not in version format rank after all versions and keep their order
+//
+// compareTagName 按前缀再按 SemVer 优先级排序标签名
+// 非版本格式的标签名排在所有版本之后并保持原有顺序
+func compareTagName(a string, b string) int {
+	aVersion, aErr := ParseTagName(a)
+	bVersion, bErr := ParseTagName(b)
+	switch {
+	case aErr != nil && bErr != nil:
+		return 0
+	case aErr != nil:
+		return 1
+	case bErr != nil:
+		return -1
+	}
+	if res := strings.Compare(aVersion.Prefix, bVersion.Prefix); res != 0 {
+		return res
+	}
+	return aVersion.Compare(bVersion)
+}
+
+// latestTagMatchRegexp finds the latest tag matching the pattern with the tag prefix
+// Version ordering picks the highest SemVer precedence, date ordering picks the latest created tag
+// Returns empty string when no tag matches
+//
+// latestTagMatchRegexp 查找匹配模式和标签前缀的最新标签
+// 版本排序选择 SemVer 优先级最高的标签，日期排序选择最新创建的标签
+// 没有标签匹配时返回空字符串
+func latestTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, tagSort TagSort) (string, error) {
+	return latestMergedTagMatchRegexp(gcm, tagPrefix, tagRegexp, tagSort, "")
+}
+
+// latestMergedTagMatchRegexp finds the latest tag like latestTagMatchRegexp, limited to the tags reachable from the merged ref
+// An empty merged ref looks at all tags of the repo
+//
+// latestMergedTagMatchRegexp 与 latestTagMatchRegexp 一样查找最新标签，但只限于从 merged 引用可达的标签
+// merged 引用为空时查看仓库的所有标签
+func latestMergedTagMatchRegexp(gcm *gitgo.Gcm, tagPrefix string, tagRegexp string, tagSort TagSort, mergedRef string) (string, error) {
+	args := []string{"tag", "--list", tagRegexp}
+	if mergedRef != "" {
+		args = append(args, "--merged", mergedRef)
+	}
+
+	switch tagSort {
+	case TagSortDate:
+		// Latest by date, preferring the final release when the latest tag is one of its pre-releases
+		// Both get the same date when the release is tagged on the pre-release commit
+		// 按日期选择最新标签，当最新标签是某个正式版本的预发布时优先使用该正式版本
+		// 当正式版本打在预发布的提交上时两者日期相同
+		var tagName string
+		if mergedRef == "" {
+			latestName, err := gcm.LatestGitTagMatchRegexp(tagRegexp)
+			if err != nil {
+				return "", erero.Wro(err)
+			}
+			tagName = latestName
+		} else {
+			output, err := runGit(gcm, append(args, "--sort=-creatordate")...)
+			if err != nil {
+				return "", erero.Wro(err)
+			}
+			tagName, _, _ = strings.Cut(output, "\n")
+		}
+		if tagName == "" {
+			return "", nil
+		}
+		return preferReleaseTag(gcm, tagName, tagPrefix), nil
+	case "", TagSortVersion:
+		output, err := runGit(gcm, args...)
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+
+		// Pick the highest version, skipping tags not in version format with this prefix
+		// 选择最高版本，跳过不符合该前缀版本格式的标签
+		var latestName string
+		var latest Version
+		for _, tagName := range strings.Split(output, "\n") {
+			version, err := Parse(tagName, tagPrefix)
+			if err != nil {
+				continue
+			}
+			if latestName == "" || version.Compare(latest) > 0 {
+				latestName = tagName
+				latest = version
+			}
+		}
+		zaplog.LOG.Debug("LATEST-TAG-BY-VERSION", zap.String("tag-regexp", tagRegexp), zap.String("merged-ref", mergedRef), zap.String("tag", latestName))
+		return latestName, nil
+	default:
+		return "", erero.WithMessagef(ErrInvalidConfig, "unknown tag sort ((%s))", tagSort)
+	}
+}
+
+// preferReleaseTag returns the final release tag when the given tag is a pre-release of an existing release
+// SemVer ranks a release above its pre-releases, so v1.3.0 wins over v1.3.0-rc.2
+//
+// preferReleaseTag 当给定标签是已存在正式版本的预发布时返回正式版本标签
+// SemVer 中正式版本优先于其预发布版本，所以 v1.3.0 优先于 v1.3.0-rc.2
+func preferReleaseTag(gcm *gitgo.Gcm, tagName string, tagPrefix string) string {
+	version, err := Parse(tagName, tagPrefix)
+	if err != nil || !version.IsPreRelease() {
+		return tagName
+	}
+	releaseTagName := version.Release().String()
+	if commitHash, err := gcm.GitCommitHash(releaseTagName); err != nil || commitHash == "" {
+		return tagName
+	}
+	zaplog.LOG.Debug("PREFER-RELEASE-TAG", zap.String("pre-release-tag", tagName), zap.String("release-tag", releaseTagName))
+	return releaseTagName
+}
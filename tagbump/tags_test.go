@@ -0,0 +1,82 @@
+package tagbump
+
+import (
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestListGitTags(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	for _, tagName := range []string{"v2.0.0", "sub/v0.1.0", "v0.0.10", "v0.0.2", "v2.0.0-rc.1", "demo"} {
+		rese.V1(execConfig.Exec("git", "tag", tagName))
+	}
+
+	tags, err := ListGitTags(gcm, TagSortVersion)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tag := range tags {
+		require.NotEmpty(t, tag.Date)
+		names = append(names, tag.Name)
+	}
+	require.Equal(t, []string{"sub/v0.1.0", "v0.0.1", "v0.0.2", "v0.0.10", "v2.0.0-rc.1", "v2.0.0", "demo"}, names)
+
+	tags, err = ListGitTags(gcm, TagSortDate)
+	require.NoError(t, err)
+	require.Len(t, tags, 7)
+
+	// Annotated tags report the commit they point to, not the tag object
+	rese.V1(execConfig.Exec("git", "tag", "--annotate", "--message", "annotated", "v3.0.0"))
+	tags, err = ListGitTags(gcm, TagSortVersion)
+	require.NoError(t, err)
+	headCommit := rese.C1(gcm.GitCommitHash("HEAD"))
+	for _, tag := range tags {
+		require.Equal(t, headCommit, tag.Commit)
+		require.False(t, tag.Time.IsZero())
+	}
+}
+
+func TestBumpMainTag_LatestByVersion(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	gcm := gitgo.New(tempDIR)
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+
+	// A hotfix tagged after v2.0.0 must not become the latest tag
+	rese.V1(execConfig.Exec("git", "tag", "v2.0.0"))
+	commitTestFile(tempDIR, "hotfix.txt")
+	rese.V1(execConfig.Exec("git", "tag", "v1.4.7"))
+	commitTestFile(tempDIR, "test.txt")
+
+	result, err := BumpMainTagWithConfig(gcm, &BumpConfig{VersionBase: 100, AutoConfirm: true, SkipGitPush: true})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("v2.0.1")))
+}
+
+func TestParseTagName(t *testing.T) {
+	version, err := ParseTagName("sub/v1.2.3-rc.1")
+	require.NoError(t, err)
+	require.Equal(t, "sub/v", version.Prefix)
+	require.Equal(t, "rc.1", version.PreRelease)
+
+	version, err = ParseTagName("release-1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "release-", version.Prefix)
+
+	_, err = ParseTagName("demo")
+	require.Error(t, err)
+
+	_, err = ParseTagName("v01.2.3")
+	require.ErrorIs(t, err, ErrTagFormat)
+}
@@ -0,0 +1,268 @@
+package tagbump
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+)
+
+// Version patterns of SemVer 2.0, numeric parts and numeric pre-release identifiers have no leading zeros
+// Build identifiers may have leading zeros, they do not take part in precedence
+//
+// SemVer 2.0 的版本模式，数字部分和数字预发布标识不能有前导零
+// 构建标识可以有前导零，它们不参与优先级比较
+const (
+	numericPattern    = `(?:0|[1-9]\d*)`
+	preReleasePattern = `(?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*)(?:\.(?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*))*`
+	buildPattern      = `[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*`
+)
+
+// Regexps compiled once from the patterns, versionRegexp matches the version after the prefix
+//
+// 由模式预先编译一次的正则，versionRegexp 匹配前缀之后的版本
+var (
+	versionRegexp    = regexp.MustCompile(`^(` + numericPattern + `)\.(` + numericPattern + `)\.(` + numericPattern + `)(?:-(` + preReleasePattern + `))?(?:\+(` + buildPattern + `))?$`)
+	tagNameRegexp    = regexp.MustCompile(`^(.*?)` + numericPattern + `\.` + numericPattern + `\.` + numericPattern + `(?:-` + preReleasePattern + `)?(?:\+` + buildPattern + `)?$`)
+	preReleaseRegexp = regexp.MustCompile(`^` + preReleasePattern + `$`)
+)
+
+// Version represents a semantic version tag with prefix, pre-release and build metadata
+// Follows SemVer 2.0: {prefix}{major}.{minor}.{patch}[-{pre-release}][+{build}]
+// Values are immutable, bump methods return new versions
+//
+// Version 表示带前缀、预发布和构建元数据的语义版本标签
+// 遵循 SemVer 2.0：{prefix}{major}.{minor}.{patch}[-{pre-release}][+{build}]
+// 值不可变，升级方法返回新版本
+type Version struct {
+	Prefix     string `json:"prefix"`      // Tag prefix (e.g., "v", "sub/v") // 标签前缀（如 "v", "sub/v"）
+	Major      int    `json:"major"`       // Major version // 主版本号
+	Minor      int    `json:"minor"`       // Minor version // 次版本号
+	Patch      int    `json:"patch"`       // Patch version // 补丁版本号
+	PreRelease string `json:"pre_release"` // Pre-release without leading '-' (e.g., "rc.1") // 不带前导 '-' 的预发布标识（如 "rc.1"）
+	Build      string `json:"build"`       // Build metadata without leading '+' // 不带前导 '+' 的构建元数据
+}
+
+// Parse parses the tag name into a Version with the given tag prefix
+// Returns an error when the tag does not match {prefix}{major}.{minor}.{patch}[-{pre}][+{build}]
+//
+// Parse 使用给定标签前缀将标签名解析为 Version
+// 当标签不匹配 {prefix}{major}.{minor}.{patch}[-{pre}][+{build}] 时返回错误
+func Parse(tagName string, tagPrefix string) (Version, error) {
+	versionText, ok := strings.CutPrefix(tagName, tagPrefix)
+	if !ok {
+		return Version{}, erero.WithMessagef(ErrTagFormat, "tag ((%s)) with tag-prefix=((%s))", tagName, tagPrefix)
+	}
+	matches := versionRegexp.FindStringSubmatch(versionText)
+	if len(matches) != 6 {
+		return Version{}, erero.WithMessagef(ErrTagFormat, "tag ((%s)) with tag-prefix=((%s))", tagName, tagPrefix)
+	}
+
+	// Convert numeric components, failing on overflow instead of panicking
+	// 转换数字组件，溢出时返回错误而非 panic
+	var numbers [3]int
+	for idx := range numbers {
+		num, err := strconv.Atoi(matches[idx+1])
+		if err != nil {
+			return Version{}, erero.WithMessagef(ErrTagFormat, "tag ((%s)) component ((%s)) out of range", tagName, matches[idx+1])
+		}
+		numbers[idx] = num
+	}
+
+	return Version{
+		Prefix:     tagPrefix,
+		Major:      numbers[0],
+		Minor:      numbers[1],
+		Patch:      numbers[2],
+		PreRelease: matches[4],
+		Build:      matches[5],
+	}, nil
+}
+
+// String returns the full tag name of the version
+//
+// String 返回版本的完整标签名
+func (v Version) String() string {
+	res := v.Prefix + v.Core()
+	if v.PreRelease != "" {
+		res += "-" + v.PreRelease
+	}
+	if v.Build != "" {
+		res += "+" + v.Build
+	}
+	return res
+}
+
+// Core returns the {major}.{minor}.{patch} part without prefix
+//
+// Core 返回不带前缀的 {major}.{minor}.{patch} 部分
+func (v Version) Core() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// IsPreRelease reports whether the version has a pre-release part
+//
+// IsPreRelease 报告版本是否带有预发布部分
+func (v Version) IsPreRelease() bool {
+	return v.PreRelease != ""
+}
+
+// Release returns the final release of the version, dropping pre-release and build metadata
+//
+// Release 返回版本的正式版本，去除预发布和构建元数据
+func (v Version) Release() Version {
+	return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// Compare compares versions by SemVer 2.0 precedence, returns -1, 0 or +1
+// Prefix and build metadata are ignored, a pre-release ranks below its release
+//
+// Compare 按 SemVer 2.0 优先级比较版本，返回 -1、0 或 +1
+// 忽略前缀和构建元数据，预发布版本低于其正式版本
+func (v Version) Compare(other Version) int {
+	if res := cmp.Compare(v.Major, other.Major); res != 0 {
+		return res
+	}
+	if res := cmp.Compare(v.Minor, other.Minor); res != 0 {
+		return res
+	}
+	if res := cmp.Compare(v.Patch, other.Patch); res != 0 {
+		return res
+	}
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+// BumpMajor returns the next major version, v1.2.3 -> v2.0.0
+// A pre-release of a major version is released instead, v2.0.0-rc.1 -> v2.0.0
+//
+// BumpMajor 返回下一个主版本，v1.2.3 -> v2.0.0
+// 主版本的预发布会直接发布，v2.0.0-rc.1 -> v2.0.0
+func (v Version) BumpMajor() Version {
+	if v.IsPreRelease() && v.Minor == 0 && v.Patch == 0 {
+		return v.Release()
+	}
+	return Version{Prefix: v.Prefix, Major: v.Major + 1}
+}
+
+// BumpMinor returns the next minor version, v1.2.3 -> v1.3.0
+// A pre-release of a minor version is released instead, v1.3.0-rc.1 -> v1.3.0
+//
+// BumpMinor 返回下一个次版本，v1.2.3 -> v1.3.0
+// 次版本的预发布会直接发布，v1.3.0-rc.1 -> v1.3.0
+func (v Version) BumpMinor() Version {
+	if v.IsPreRelease() && v.Patch == 0 {
+		return v.Release()
+	}
+	return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor + 1}
+}
+
+// BumpPatch returns the next patch version, v1.2.3 -> v1.2.4
+// A pre-release is released instead, v1.2.4-rc.1 -> v1.2.4
+//
+// BumpPatch 返回下一个补丁版本，v1.2.3 -> v1.2.4
+// 预发布会直接发布，v1.2.4-rc.1 -> v1.2.4
+func (v Version) BumpPatch() Version {
+	if v.IsPreRelease() {
+		return v.Release()
+	}
+	return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// Bump returns the next version at the given bump level, empty level means patch
+//
+// Bump 返回给定升级级别的下一个版本，空级别表示补丁
+func (v Version) Bump(bumpLevel BumpLevel) (Version, error) {
+	switch bumpLevel {
+	case BumpMajor:
+		return v.BumpMajor(), nil
+	case BumpMinor:
+		return v.BumpMinor(), nil
+	case "", BumpPatch:
+		return v.BumpPatch(), nil
+	default:
+		return Version{}, erero.WithMessagef(ErrInvalidConfig, "unknown bump level ((%s))", bumpLevel)
+	}
+}
+
+// BumpPreRelease returns the next pre-release at the given bump level with the identifier
+// Continues the counter when the current pre-release already stands for the bump (v1.3.0-rc.1 -> v1.3.0-rc.2)
+// Otherwise bumps the core version and starts at 1 (v1.2.0 -> v1.3.0-rc.1)
+//
+// BumpPreRelease 返回给定升级级别和标识的下一个预发布版本
+// 当前预发布已代表这次升级时继续递增计数器（v1.3.0-rc.1 -> v1.3.0-rc.2）
+// 否则升级核心版本并从 1 开始（v1.2.0 -> v1.3.0-rc.1）
+func (v Version) BumpPreRelease(bumpLevel BumpLevel, identifier string) (Version, error) {
+	if !preReleaseRegexp.MatchString(identifier) {
+		return Version{}, erero.WithMessagef(ErrInvalidConfig, "wrong pre-release identifier ((%s))", identifier)
+	}
+	next, err := v.Bump(bumpLevel)
+	if err != nil {
+		return Version{}, erero.Wro(err)
+	}
+	// A pre-release bumped to its own release keeps the core, so continue its counter
+	// 预发布升级到自身正式版本时核心版本不变，因此继续其计数器
+	if v.IsPreRelease() && next.Core() == v.Core() {
+		next.PreRelease = nextPreRelease(v.PreRelease, identifier)
+	} else {
+		next.PreRelease = identifier + ".1"
+	}
+	return next, nil
+}
+
+// nextPreRelease computes the next pre-release on the same core version
+// Increments the trailing counter when the identifier matches (rc.1 -> rc.2), else restarts at 1 (beta.3 -> rc.1)
+//
+// nextPreRelease 计算相同核心版本上的下一个预发布标识
+// 标识相同时递增末尾计数器（rc.1 -> rc.2），否则从 1 重新开始（beta.3 -> rc.1）
+func nextPreRelease(preRelease string, identifier string) string {
+	if preRelease == identifier {
+		return identifier + ".1"
+	}
+	if strings.HasPrefix(preRelease, identifier+".") {
+		if num, err := strconv.Atoi(strings.TrimPrefix(preRelease, identifier+".")); err == nil {
+			return fmt.Sprintf("%s.%d", identifier, num+1)
+		}
+	}
+	return identifier + ".1"
+}
+
+// comparePreRelease compares pre-release strings by SemVer 2.0 precedence rules
+// An empty pre-release (release) ranks higher, numeric identifiers rank below alphanumeric ones
+//
+// comparePreRelease 按 SemVer 2.0 优先级规则比较预发布字符串
+// 空预发布（正式版本）优先级更高，数字标识低于字母数字标识
+func comparePreRelease(a string, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for idx := 0; idx < len(aParts) && idx < len(bParts); idx++ {
+		aNum, aErr := strconv.Atoi(aParts[idx])
+		bNum, bErr := strconv.Atoi(bParts[idx])
+		switch {
+		case aErr == nil && bErr == nil:
+			if res := cmp.Compare(aNum, bNum); res != 0 {
+				return res
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if res := strings.Compare(aParts[idx], bParts[idx]); res != 0 {
+				return res
+			}
+		}
+	}
+	return cmp.Compare(len(aParts), len(bParts))
+}
@@ -0,0 +1,161 @@
+package tagbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+func TestParse(t *testing.T) {
+	version, err := Parse("sub/v1.2.3-rc.1+build.5", "sub/v")
+	require.NoError(t, err)
+	require.Equal(t, Version{Prefix: "sub/v", Major: 1, Minor: 2, Patch: 3, PreRelease: "rc.1", Build: "build.5"}, version)
+	require.Equal(t, "sub/v1.2.3-rc.1+build.5", version.String())
+	require.True(t, version.IsPreRelease())
+	require.Equal(t, "sub/v1.2.3", version.Release().String())
+
+	_, err = Parse("v1.2", "v")
+	require.Error(t, err)
+
+	_, err = Parse("sub/v1.2.3", "v")
+	require.Error(t, err)
+
+	_, err = Parse("v1.2.99999999999999999999", "v")
+	require.Error(t, err)
+
+	// Numeric parts and numeric pre-release identifiers have no leading zeros, build metadata may have them
+	for _, tagName := range []string{"v01.2.3", "v1.02.3", "v1.2.03", "v1.2.3-rc.01", "v1.2.3-01"} {
+		_, err = Parse(tagName, "v")
+		require.ErrorIs(t, err, ErrTagFormat, tagName)
+	}
+	version, err = Parse("v0.10.0-rc.0a.10+build.007", "v")
+	require.NoError(t, err)
+	require.Equal(t, "rc.0a.10", version.PreRelease)
+	require.Equal(t, "build.007", version.Build)
+}
+
+func TestVersion_Compare(t *testing.T) {
+	// Ordered by SemVer 2.0 precedence, taken from the SemVer spec example
+	tagNames := []string{
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.1.0",
+		"v2.0.0",
+	}
+	for idx := 1; idx < len(tagNames); idx++ {
+		prev, err := Parse(tagNames[idx-1], "v")
+		require.NoError(t, err)
+		next, err := Parse(tagNames[idx], "v")
+		require.NoError(t, err)
+
+		require.Equal(t, -1, prev.Compare(next), "%s < %s", prev, next)
+		require.Equal(t, 1, next.Compare(prev), "%s > %s", next, prev)
+	}
+
+	a, err := Parse("v1.0.0+build.1", "v")
+	require.NoError(t, err)
+	b, err := Parse("v1.0.0+build.2", "v")
+	require.NoError(t, err)
+	require.Equal(t, 0, a.Compare(b))
+}
+
+func TestVersion_Bump(t *testing.T) {
+	testCases := []struct {
+		tagName    string
+		bumpLevel  BumpLevel
+		preRelease string
+		expected   string
+	}{
+		{tagName: "v1.2.3", bumpLevel: BumpPatch, expected: "v1.2.4"},
+		{tagName: "v1.2.3", bumpLevel: BumpMinor, expected: "v1.3.0"},
+		{tagName: "v1.2.3", bumpLevel: BumpMajor, expected: "v2.0.0"},
+		{tagName: "v1.2.3+build", bumpLevel: "", expected: "v1.2.4"},
+		{tagName: "v1.3.0-rc.2", bumpLevel: BumpPatch, expected: "v1.3.0"},
+		{tagName: "v1.3.0-rc.2", bumpLevel: BumpMinor, expected: "v1.3.0"},
+		{tagName: "v1.3.0-rc.2", bumpLevel: BumpMajor, expected: "v2.0.0"},
+		{tagName: "v1.2.0", bumpLevel: BumpMinor, preRelease: "rc", expected: "v1.3.0-rc.1"},
+		{tagName: "v1.3.0-rc.1", bumpLevel: "", preRelease: "rc", expected: "v1.3.0-rc.2"},
+		{tagName: "v1.3.0-beta.4", bumpLevel: BumpMinor, preRelease: "rc", expected: "v1.3.0-rc.1"},
+		{tagName: "v1.3.1-rc.1", bumpLevel: BumpMinor, preRelease: "rc", expected: "v1.4.0-rc.1"},
+	}
+	for _, tc := range testCases {
+		version, err := Parse(tc.tagName, "v")
+		require.NoError(t, err)
+
+		var next Version
+		if tc.preRelease != "" {
+			next, err = version.BumpPreRelease(tc.bumpLevel, tc.preRelease)
+		} else {
+			next, err = version.Bump(tc.bumpLevel)
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, next.String(), "%s %s %s", tc.tagName, tc.bumpLevel, tc.preRelease)
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	version, err := Parse("v1.9.9", "v")
+	require.NoError(t, err)
+
+	next, err := NextVersion(version, &BumpConfig{VersionBase: 10})
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", next.String())
+
+	next, err = NextVersion(version, &BumpConfig{VersionBase: 10, PreRelease: "rc"})
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0-rc.1", next.String())
+
+	// Switching back to a lower pre-release identifier is refused
+	version, err = Parse("v1.3.0-rc.1", "v")
+	require.NoError(t, err)
+	_, err = NextVersion(version, &BumpConfig{PreRelease: "beta"})
+	require.Error(t, err)
+}
+
+func TestNextVersion_ComponentBases(t *testing.T) {
+	// Patch rolls over at 100, minor at 10
+	config := &BumpConfig{PatchBase: 100, MinorBase: 10}
+
+	next, err := NextVersion(rese.V1(Parse("v1.8.99", "v")), config)
+	require.NoError(t, err)
+	require.Equal(t, "v1.9.0", next.String())
+
+	next, err = NextVersion(rese.V1(Parse("v1.9.99", "v")), config)
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", next.String())
+
+	next, err = NextVersion(rese.V1(Parse("v1.9.9", "v")), config)
+	require.NoError(t, err)
+	require.Equal(t, "v1.9.10", next.String())
+
+	// Component bases fall back to VersionBase
+	next, err = NextVersion(rese.V1(Parse("v1.9.9", "v")), &BumpConfig{VersionBase: 10, PatchBase: 100})
+	require.NoError(t, err)
+	require.Equal(t, "v1.9.10", next.String())
+}
+
+func TestBumpConfig_CheckVersionBase(t *testing.T) {
+	config := &BumpConfig{PatchBase: 100, MinorBase: 10}
+	require.NoError(t, config.checkVersionBase(rese.V1(Parse("v1.9.99", "v"))))
+	require.ErrorIs(t, config.checkVersionBase(rese.V1(Parse("v1.10.0", "v"))), ErrVersionBaseOverflow)
+	require.ErrorIs(t, config.checkVersionBase(rese.V1(Parse("v1.0.100", "v"))), ErrVersionBaseOverflow)
+
+	// No limit below 2
+	require.NoError(t, (&BumpConfig{}).checkVersionBase(rese.V1(Parse("v1.100.100", "v"))))
+	require.ErrorIs(t, (&BumpConfig{PatchBase: -1}).checkVersionBase(rese.V1(Parse("v1.0.0", "v"))), ErrInvalidConfig)
+}
+
+func TestNextPreRelease(t *testing.T) {
+	require.Equal(t, "rc.2", nextPreRelease("rc.1", "rc"))
+	require.Equal(t, "rc.1", nextPreRelease("rc", "rc"))
+	require.Equal(t, "rc.1", nextPreRelease("beta.3", "rc"))
+	require.Equal(t, "rc.1", nextPreRelease("", "rc"))
+}
@@ -0,0 +1,178 @@
+package tagbump
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+)
+
+// VersionFileKind tells how a version file is rewritten
+//
+// VersionFileKind 表示版本文件的改写方式
+type VersionFileKind string
+
+const (
+	VersionFileGoConst VersionFileKind = "go"     // Go string constant or variable, rewritten via go/ast // Go 字符串常量或变量，通过 go/ast 改写
+	VersionFileText    VersionFileKind = "text"   // Plain text file holding only the version // 仅包含版本号的纯文本文件
+	VersionFileRegexp  VersionFileKind = "regexp" // First capture group of each regexp match // 每个正则匹配的第一个捕获组
+)
+
+// VersionFile is a file rewritten with the new version in the release commit
+//
+// VersionFile 是在发布提交中写入新版本的文件
+type VersionFile struct {
+	Path    string          // File path relative to the module DIR // 相对于模块目录的文件路径
+	Kind    VersionFileKind // How the file is rewritten // 文件的改写方式
+	Name    string          // Go constant name (empty = Version) // Go 常量名（空值 = Version）
+	Pattern string          // Regexp whose first capture group is the version // 第一个捕获组为版本号的正则表达式
+}
+
+// ParseVersionFile parses a version file spec of the CLI
+// "version.go" and "version.go:Name" rewrite a Go constant, "path=regexp" rewrites regexp matches,
+// any other path is a plain text file
+//
+// ParseVersionFile 解析 CLI 的版本文件描述
+// "version.go" 和 "version.go:Name" 改写 Go 常量，"path=regexp" 改写正则匹配，
+// 其它路径为纯文本文件
+func ParseVersionFile(spec string) (VersionFile, error) {
+	if filePath, pattern, ok := strings.Cut(spec, "="); ok {
+		if filePath == "" || pattern == "" {
+			return VersionFile{}, erero.Errorf("wrong version file ((%s)), want path=regexp", spec)
+		}
+		return VersionFile{Path: filePath, Kind: VersionFileRegexp, Pattern: pattern}, nil
+	}
+	if filePath, name, ok := strings.Cut(spec, ":"); ok && strings.HasSuffix(filePath, ".go") {
+		if !token.IsIdentifier(name) {
+			return VersionFile{}, erero.Errorf("wrong constant name ((%s)) in version file ((%s))", name, spec)
+		}
+		return VersionFile{Path: filePath, Kind: VersionFileGoConst, Name: name}, nil
+	}
+	if spec == "" {
+		return VersionFile{}, erero.New("empty version file")
+	}
+	if filepath.Ext(spec) == ".go" {
+		return VersionFile{Path: spec, Kind: VersionFileGoConst}, nil
+	}
+	return VersionFile{Path: spec, Kind: VersionFileText}, nil
+}
+
+// Rewrite returns the content with the version replaced
+// The "v" prefix is dropped when the old value has none, e.g. a VERSION file holding "1.2.3"
+//
+// Rewrite 返回替换版本号后的内容
+// 旧值没有 "v" 前缀时去掉该前缀，例如内容为 "1.2.3" 的 VERSION 文件
+func (file VersionFile) Rewrite(content []byte, version string) ([]byte, error) {
+	switch file.Kind {
+	case VersionFileGoConst:
+		return rewriteGoConst(file.Path, content, file.constName(), version)
+	case "", VersionFileText:
+		old := strings.TrimSpace(string(content))
+		return []byte(versionValue(old, version) + "\n"), nil
+	case VersionFileRegexp:
+		return rewriteRegexp(content, file.Pattern, version)
+	default:
+		return nil, erero.Errorf("unknown version file kind ((%s))", file.Kind)
+	}
+}
+
+// constName returns the Go constant name, Version by default
+//
+// constName 返回 Go 常量名，默认为 Version
+func (file VersionFile) constName() string {
+	if file.Name == "" {
+		return "Version"
+	}
+	return file.Name
+}
+
+// rewriteGoConst replaces the string literal of the named constant or variable in Go source
+// Only the literal bytes change, so formatting and comments are kept as is
+//
+// rewriteGoConst 替换 Go 源码中指定常量或变量的字符串字面量
+// 仅修改字面量的字节，格式和注释保持不变
+func rewriteGoConst(fileName string, content []byte, name string, version string) ([]byte, error) {
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, fileName, content, parser.ParseComments)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	for _, decl := range astFile.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || (genDecl.Tok != token.CONST && genDecl.Tok != token.VAR) {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for idx, ident := range valueSpec.Names {
+				if ident.Name != name || idx >= len(valueSpec.Values) {
+					continue
+				}
+				literal, ok := valueSpec.Values[idx].(*ast.BasicLit)
+				if !ok || literal.Kind != token.STRING {
+					return nil, erero.Errorf("((%s)) in ((%s)) is not a string literal", name, fileName)
+				}
+				old, err := strconv.Unquote(literal.Value)
+				if err != nil {
+					return nil, erero.Wro(err)
+				}
+				start := fset.Position(literal.Pos()).Offset
+				end := fset.Position(literal.End()).Offset
+
+				var buf bytes.Buffer
+				buf.Write(content[:start])
+				buf.WriteString(strconv.Quote(versionValue(old, version)))
+				buf.Write(content[end:])
+				return buf.Bytes(), nil
+			}
+		}
+	}
+	return nil, erero.Errorf("no string constant ((%s)) in ((%s))", name, fileName)
+}
+
+// rewriteRegexp replaces the first capture group of every match of the pattern
+//
+// rewriteRegexp 替换模式每个匹配中的第一个捕获组
+func rewriteRegexp(content []byte, pattern string, version string) ([]byte, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if re.NumSubexp() < 1 {
+		return nil, erero.Errorf("pattern ((%s)) has no capture group for the version", pattern)
+	}
+	matches := re.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, erero.Errorf("pattern ((%s)) matches nothing", pattern)
+	}
+
+	var buf bytes.Buffer
+	offset := 0
+	for _, match := range matches {
+		start, end := match[2], match[3]
+		if start < 0 {
+			continue
+		}
+		buf.Write(content[offset:start])
+		buf.WriteString(versionValue(string(content[start:end]), version))
+		offset = end
+	}
+	buf.Write(content[offset:])
+	return buf.Bytes(), nil
+}
+
+// versionValue drops the "v" prefix of the version when the old value has none
+//
+// versionValue 旧值没有 "v" 前缀时去掉版本号的 "v" 前缀
+func versionValue(old string, version string) string {
+	if old != "" && !strings.HasPrefix(old, "v") {
+		return strings.TrimPrefix(version, "v")
+	}
+	return version
+}
@@ -0,0 +1,89 @@
+package tagbump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+func TestParseVersionFile(t *testing.T) {
+	require.Equal(t, VersionFile{Path: "version.go", Kind: VersionFileGoConst}, rese.V1(ParseVersionFile("version.go")))
+	require.Equal(t, VersionFile{Path: "internal/version.go", Kind: VersionFileGoConst, Name: "AppVersion"}, rese.V1(ParseVersionFile("internal/version.go:AppVersion")))
+	require.Equal(t, VersionFile{Path: "VERSION", Kind: VersionFileText}, rese.V1(ParseVersionFile("VERSION")))
+	require.Equal(t, VersionFile{Path: "chart.yaml", Kind: VersionFileRegexp, Pattern: `appVersion: (\S+)`}, rese.V1(ParseVersionFile(`chart.yaml=appVersion: (\S+)`)))
+
+	_, err := ParseVersionFile("version.go:not-a-name")
+	require.Error(t, err)
+	_, err = ParseVersionFile("chart.yaml=")
+	require.Error(t, err)
+}
+
+func TestVersionFile_Rewrite(t *testing.T) {
+	source := "package main\n\n// Version is the release version\nconst Version = \"v1.2.3\" // keep comment\n\nvar (\n\tName       = \"demo\"\n\tAppVersion = `1.2.3`\n)\n"
+
+	content, err := VersionFile{Path: "version.go", Kind: VersionFileGoConst}.Rewrite([]byte(source), "v1.3.0")
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(source, `"v1.2.3"`, `"v1.3.0"`, 1), string(content))
+
+	// The old value has no "v" prefix, so the new one has none either
+	content, err = VersionFile{Path: "version.go", Kind: VersionFileGoConst, Name: "AppVersion"}.Rewrite([]byte(source), "v1.3.0")
+	require.NoError(t, err)
+	require.Contains(t, string(content), "AppVersion = \"1.3.0\"")
+
+	_, err = VersionFile{Path: "version.go", Kind: VersionFileGoConst, Name: "Missing"}.Rewrite([]byte(source), "v1.3.0")
+	require.Error(t, err)
+
+	content, err = VersionFile{Path: "VERSION", Kind: VersionFileText}.Rewrite([]byte("1.2.3\n"), "v1.3.0")
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0\n", string(content))
+
+	content, err = VersionFile{Path: "VERSION", Kind: VersionFileText}.Rewrite(nil, "v1.3.0")
+	require.NoError(t, err)
+	require.Equal(t, "v1.3.0\n", string(content))
+
+	content, err = VersionFile{Path: "chart.yaml", Kind: VersionFileRegexp, Pattern: `appVersion: (\S+)`}.Rewrite([]byte("name: demo\nappVersion: v1.2.3\n"), "v1.3.0")
+	require.NoError(t, err)
+	require.Equal(t, "name: demo\nappVersion: v1.3.0\n", string(content))
+
+	_, err = VersionFile{Path: "chart.yaml", Kind: VersionFileRegexp, Pattern: `appVersion: \S+`}.Rewrite([]byte("appVersion: v1.2.3\n"), "v1.3.0")
+	require.Error(t, err)
+	_, err = VersionFile{Path: "chart.yaml", Kind: VersionFileRegexp, Pattern: `version: (\S+)`}.Rewrite([]byte("name: demo\n"), "v1.3.0")
+	require.Error(t, err)
+}
+
+func TestBumpSubModuleTag_VersionFiles(t *testing.T) {
+	tempDIR, cleanup := setupTestRepo()
+	defer cleanup()
+
+	execConfig := osexec.NewExecConfig().WithPath(tempDIR)
+	subDIR := filepath.Join(tempDIR, "sub")
+	must.Done(os.MkdirAll(subDIR, 0755))
+	must.Done(os.WriteFile(filepath.Join(subDIR, "go.mod"), []byte("module example.com/sub\n"), 0644))
+	must.Done(os.WriteFile(filepath.Join(subDIR, "version.go"), []byte("package sub\n\nconst Version = \"v0.1.0\"\n"), 0644))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "chore: add sub"))
+	rese.V1(execConfig.Exec("git", "tag", "sub/v0.1.0"))
+	commitTestFile(tempDIR, "test.txt")
+
+	gcm := gitgo.New(subDIR)
+	result, err := BumpSubModuleTagWithConfig(gcm, &BumpConfig{
+		VersionFiles: []VersionFile{{Path: "version.go", Kind: VersionFileGoConst}, {Path: "VERSION", Kind: VersionFileText}},
+		AutoConfirm:  true,
+		SkipGitPush:  true,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Created)
+
+	// The tagged source reports its own version
+	require.Equal(t, rese.C1(gcm.GitCommitHash("HEAD")), rese.C1(gcm.GitCommitHash("sub/v0.1.1")))
+	require.Equal(t, "package sub\n\nconst Version = \"v0.1.1\"\n", string(rese.V1(execConfig.Exec("git", "show", "sub/v0.1.1:sub/version.go"))))
+	require.Equal(t, "v0.1.1\n", string(rese.V1(execConfig.Exec("git", "show", "sub/v0.1.1:sub/VERSION"))))
+	require.Equal(t, "chore(release): sub/v0.1.1", strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "log", "-1", "--format=%s")))))
+}